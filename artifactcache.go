@@ -0,0 +1,341 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// artifactCacheEnv names the shared cache directory when cache.dir is not set.
+	artifactCacheEnv = "MODERNE_INSTALLER_CACHE"
+
+	objectsDirName = "sha256"
+	indexDirName   = "versions"
+)
+
+// artifactCache is a content-addressed JAR store shared by several install
+// directories, for example all users or containers on a build host. JARs are
+// stored under sha256/<digest>.jar; versions/<version> names the digest of
+// each cached version.
+type artifactCache struct {
+	dir    string
+	logger *Logger
+}
+
+// cachedArtifact is one version in the cache.
+type cachedArtifact struct {
+	version string
+	digest  string
+	size    int64
+	missing bool
+}
+
+// newArtifactCache returns the configured shared cache, or nil if there is none.
+func newArtifactCache(config *Config, logger *Logger) *artifactCache {
+	dir := config.ArtifactCacheDir()
+	if dir == "" {
+		return nil
+	}
+	return &artifactCache{dir: dir, logger: logger}
+}
+
+func (c *artifactCache) objectPath(digest string) string {
+	return filepath.Join(c.dir, objectsDirName, digest+jarFileSuffix)
+}
+
+func (c *artifactCache) indexPath(version string) string {
+	return filepath.Join(c.dir, indexDirName, version)
+}
+
+// lookup returns the cached JAR for a version. The cached file is always
+// rehashed against its digest, since anyone sharing the cache can write to it;
+// a corrupt entry is removed and treated as a miss. When the repository
+// publishes a checksum, the cached file must match it as well.
+func (c *artifactCache) lookup(version string, expected *expectedChecksum) (string, bool) {
+	content, err := os.ReadFile(c.indexPath(version))
+	if err != nil {
+		return "", false
+	}
+	digest := strings.TrimSpace(string(content))
+	path := c.objectPath(digest)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	// Hash once for both the digest and a published checksum of another algorithm
+	h := sha256.New()
+	var published hash.Hash
+	if expected != nil && (expected.algorithm.name != "sha256" || expected.value != digest) {
+		published = expected.newHash()
+	}
+	var w io.Writer = h
+	if published != nil {
+		w = io.MultiWriter(h, published)
+	}
+	if err := hashFile(w, path); err != nil {
+		return "", false
+	}
+
+	if hex.EncodeToString(h.Sum(nil)) != digest {
+		c.logger.Warning("Cached %s is corrupt, removing it and downloading again", version)
+		os.Remove(path)
+		os.Remove(c.indexPath(version))
+		return "", false
+	}
+	if published != nil && expected.verify(published) != nil {
+		c.logger.Warning("Cached %s does not match the published %s checksum, downloading again", version, expected.algorithm.name)
+		return "", false
+	}
+
+	return path, true
+}
+
+// store adds a verified JAR to the cache under its SHA-256 digest. Stored
+// objects are made read-only: installs are hard links to them, so a write to
+// an object would change every install linked to it.
+func (c *artifactCache) store(version, jarPath string) error {
+	h := sha256.New()
+	if err := hashFile(h, jarPath); err != nil {
+		return err
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	object := c.objectPath(digest)
+	if _, err := os.Stat(object); err != nil {
+		if err := os.MkdirAll(filepath.Dir(object), 0775); err != nil {
+			return err
+		}
+		if err := linkOrCopy(jarPath, object); err != nil {
+			return err
+		}
+		if err := os.Chmod(object, 0444); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.dir, indexDirName), 0775); err != nil {
+		return err
+	}
+	return writeFileAtomic(c.indexPath(version), []byte(digest+"\n"))
+}
+
+// list returns the cached versions, oldest first.
+func (c *artifactCache) list() ([]cachedArtifact, error) {
+	entries, err := os.ReadDir(filepath.Join(c.dir, indexDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasSuffix(entry.Name(), partFileSuffix) {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sortVersions(versions)
+
+	var artifacts []cachedArtifact
+	for _, version := range versions {
+		content, err := os.ReadFile(c.indexPath(version))
+		if err != nil {
+			return nil, err
+		}
+		artifact := cachedArtifact{version: version, digest: strings.TrimSpace(string(content))}
+		if info, err := os.Stat(c.objectPath(artifact.digest)); err == nil {
+			artifact.size = info.Size()
+		} else {
+			artifact.missing = true
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+// prune keeps the newest keep versions and deletes the rest, along with any
+// stored JAR no longer referenced by a version. It returns the removed versions.
+func (c *artifactCache) prune(keep int) ([]string, error) {
+	artifacts, err := c.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	referenced := map[string]bool{}
+	for n, artifact := range artifacts {
+		if n < len(artifacts)-keep {
+			if err := os.Remove(c.indexPath(artifact.version)); err != nil {
+				return removed, err
+			}
+			removed = append(removed, artifact.version)
+			continue
+		}
+		referenced[artifact.digest] = true
+	}
+
+	objects, err := os.ReadDir(filepath.Join(c.dir, objectsDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return removed, err
+	}
+	for _, object := range objects {
+		if !referenced[strings.TrimSuffix(object.Name(), jarFileSuffix)] {
+			if err := os.Remove(filepath.Join(c.dir, objectsDirName, object.Name())); err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
+
+// verify rehashes every stored JAR and deletes those whose content no longer
+// matches their digest, so the next install downloads them again. It returns
+// the affected versions.
+func (c *artifactCache) verify() ([]string, error) {
+	artifacts, err := c.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var corrupt []string
+	for _, artifact := range artifacts {
+		if artifact.missing {
+			corrupt = append(corrupt, artifact.version)
+			os.Remove(c.indexPath(artifact.version))
+			continue
+		}
+
+		h := sha256.New()
+		if err := hashFile(h, c.objectPath(artifact.digest)); err != nil {
+			return corrupt, err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != artifact.digest {
+			corrupt = append(corrupt, artifact.version)
+			os.Remove(c.objectPath(artifact.digest))
+			os.Remove(c.indexPath(artifact.version))
+		}
+	}
+	return corrupt, nil
+}
+
+// runCache implements the cache subcommand: cache list|prune|verify.
+func runCache(config *Config, args []string, out io.Writer) error {
+	logger := NewLogger()
+	cache := newArtifactCache(config, logger)
+	if cache == nil {
+		return fmt.Errorf("no shared cache configured; set cache.dir or $%s", artifactCacheEnv)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: cache list|prune --keep N|verify")
+	}
+
+	switch args[0] {
+	case "list":
+		artifacts, err := cache.list()
+		if err != nil {
+			return err
+		}
+		if len(artifacts) == 0 {
+			fmt.Fprintf(out, "No cached versions in %s\n", cache.dir)
+			return nil
+		}
+		for _, artifact := range artifacts {
+			status := fmt.Sprintf("%.2f MB", float64(artifact.size)/(1024*1024))
+			if artifact.missing {
+				status = "missing"
+			}
+			fmt.Fprintf(out, "%-16s %s  %s\n", artifact.version, artifact.digest[:min(12, len(artifact.digest))], status)
+		}
+		return nil
+
+	case "prune":
+		flags := flag.NewFlagSet("cache prune", flag.ExitOnError)
+		keep := flags.Int("keep", -1, "Number of newest versions to keep (required)")
+		flags.Parse(args[1:])
+		if *keep < 0 {
+			return fmt.Errorf("--keep is required")
+		}
+
+		removed, err := cache.prune(*keep)
+		for _, version := range removed {
+			fmt.Fprintf(out, "Removed %s\n", version)
+		}
+		return err
+
+	case "verify":
+		corrupt, err := cache.verify()
+		if err != nil {
+			return err
+		}
+		if len(corrupt) > 0 {
+			return fmt.Errorf("removed corrupt cache entries: %s", strings.Join(corrupt, ", "))
+		}
+		fmt.Fprintln(out, "All cached JARs are intact")
+		return nil
+	}
+
+	return fmt.Errorf("unknown cache command %q; expected list, prune or verify", args[0])
+}
+
+// linkOrCopy hard-links src to dst, copying when a link is not possible
+// (for example across file systems). dst appears atomically. A linked dst
+// shares its inode, and so its content and mode, with src.
+func linkOrCopy(src, dst string) error {
+	tmp := dst + partFileSuffix
+	os.Remove(tmp)
+	if err := os.Link(src, tmp); err != nil {
+		if err := copyFile(src, tmp); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// copyFile copies src to dst and syncs it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// hashFile feeds the whole file into h.
+func hashFile(h io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
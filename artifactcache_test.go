@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedInstaller(t *testing.T, baseURL string, cache *artifactCache) *Installer {
+	binDir := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	return &Installer{
+		version:     "1.0.0",
+		config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: baseURL}},
+		binDir:      binDir,
+		jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+		jarFileName: "moderne-cli-1.0.0.jar",
+		cache:       cache,
+		logger:      NewLogger(),
+	}
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArtifactCacheInstall(t *testing.T) {
+	content := []byte("fake jar content")
+	checksum := sha256Hex(content)
+	jarRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1.0.0/moderne-cli-1.0.0.jar":
+			jarRequests++
+			w.Write(content)
+		case "/1.0.0/moderne-cli-1.0.0.jar.sha256":
+			w.Write([]byte(checksum))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cache := &artifactCache{dir: t.TempDir(), logger: NewLogger()}
+
+	first := newCachedInstaller(t, server.URL, cache)
+	require.NoError(t, first.downloadJAR())
+	assert.Equal(t, 1, jarRequests)
+	assert.FileExists(t, filepath.Join(cache.dir, "sha256", checksum+".jar"))
+
+	second := newCachedInstaller(t, server.URL, cache)
+	require.NoError(t, second.downloadJAR())
+	assert.Equal(t, 1, jarRequests, "second install should come from the cache")
+
+	installed, err := os.ReadFile(second.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, installed)
+
+	cachedInfo, err := os.Stat(cache.objectPath(checksum))
+	require.NoError(t, err)
+	installedInfo, err := os.Stat(second.jarPath)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(cachedInfo, installedInfo), "expected a hard link")
+}
+
+func TestArtifactCacheLookup(t *testing.T) {
+	cache := &artifactCache{dir: t.TempDir(), logger: NewLogger()}
+	jarPath := filepath.Join(t.TempDir(), "moderne-cli-1.0.0.jar")
+	content := []byte("fake jar content")
+	require.NoError(t, os.WriteFile(jarPath, content, 0644))
+	require.NoError(t, cache.store("1.0.0", jarPath))
+
+	t.Run("hits without a published checksum", func(t *testing.T) {
+		path, ok := cache.lookup("1.0.0", nil)
+		assert.True(t, ok)
+		assert.Equal(t, cache.objectPath(sha256Hex(content)), path)
+	})
+
+	t.Run("verifies other checksum algorithms", func(t *testing.T) {
+		expected := &expectedChecksum{algorithm: checksumAlgorithms[0]}
+		h := expected.newHash()
+		h.Write(content)
+		expected.value = hex.EncodeToString(h.Sum(nil))
+
+		_, ok := cache.lookup("1.0.0", expected)
+		assert.True(t, ok)
+	})
+
+	t.Run("misses when the published checksum differs", func(t *testing.T) {
+		expected := &expectedChecksum{algorithm: checksumAlgorithms[1], value: sha256Hex([]byte("other"))}
+		_, ok := cache.lookup("1.0.0", expected)
+		assert.False(t, ok)
+	})
+
+	t.Run("misses for unknown versions", func(t *testing.T) {
+		_, ok := cache.lookup("2.0.0", nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("stores read-only objects", func(t *testing.T) {
+		info, err := os.Stat(cache.objectPath(sha256Hex(content)))
+		require.NoError(t, err)
+		assert.Zero(t, info.Mode().Perm()&0222, "cache object should not be writable")
+	})
+}
+
+// tamperObject overwrites a read-only cache object.
+func tamperObject(t *testing.T, path, content string) {
+	require.NoError(t, os.Chmod(path, 0644))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestArtifactCacheTamperedObject(t *testing.T) {
+	content := []byte("fake jar content")
+	digest := sha256Hex(content)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/1.0.0/moderne-cli-1.0.0.jar" {
+			w.Write(content)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cache := &artifactCache{dir: t.TempDir(), logger: NewLogger()}
+	storeVersions(t, cache, map[string]string{"1.0.0": string(content)})
+	tamperObject(t, cache.objectPath(digest), "tampered")
+
+	_, ok := cache.lookup("1.0.0", nil)
+	assert.False(t, ok, "a tampered object must not be used without a published checksum")
+	assert.NoFileExists(t, cache.objectPath(digest))
+
+	// The next install downloads again and repairs the cache entry
+	installer := newCachedInstaller(t, server.URL, cache)
+	require.NoError(t, installer.downloadJAR())
+	installed, err := os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, installed)
+
+	path, ok := cache.lookup("1.0.0", nil)
+	assert.True(t, ok)
+	assert.Equal(t, cache.objectPath(digest), path)
+}
+
+// storeVersions adds a JAR per version to the cache; equal contents share an object.
+func storeVersions(t *testing.T, cache *artifactCache, contents map[string]string) {
+	dir := t.TempDir()
+	for version, content := range contents {
+		path := filepath.Join(dir, version+".jar")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		require.NoError(t, cache.store(version, path))
+	}
+}
+
+func TestArtifactCachePrune(t *testing.T) {
+	cache := &artifactCache{dir: t.TempDir(), logger: NewLogger()}
+	storeVersions(t, cache, map[string]string{
+		"3.57.9":  "a",
+		"3.57.10": "b",
+		"3.9.0":   "c",
+		"3.58.0":  "b",
+	})
+
+	removed, err := cache.prune(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.9.0", "3.57.9"}, removed)
+
+	artifacts, err := cache.list()
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+	assert.Equal(t, "3.57.10", artifacts[0].version)
+	assert.Equal(t, "3.58.0", artifacts[1].version)
+
+	objects, err := os.ReadDir(filepath.Join(cache.dir, "sha256"))
+	require.NoError(t, err)
+	assert.Len(t, objects, 1, "only the shared object should remain")
+}
+
+func TestArtifactCacheVerify(t *testing.T) {
+	cache := &artifactCache{dir: t.TempDir(), logger: NewLogger()}
+	storeVersions(t, cache, map[string]string{"1.0.0": "good", "2.0.0": "bad"})
+
+	tamperObject(t, cache.objectPath(sha256Hex([]byte("bad"))), "tampered")
+
+	corrupt, err := cache.verify()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0"}, corrupt)
+
+	_, ok := cache.lookup("2.0.0", nil)
+	assert.False(t, ok)
+	_, ok = cache.lookup("1.0.0", nil)
+	assert.True(t, ok)
+}
+
+func TestRunCache(t *testing.T) {
+	t.Run("requires a configured cache", func(t *testing.T) {
+		t.Setenv(artifactCacheEnv, "")
+		err := runCache(&Config{}, []string{"list"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "no shared cache configured")
+	})
+
+	t.Run("uses the environment variable", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv(artifactCacheEnv, dir)
+		storeVersions(t, &artifactCache{dir: dir, logger: NewLogger()}, map[string]string{"1.0.0": "content"})
+
+		var out bytes.Buffer
+		require.NoError(t, runCache(&Config{}, []string{"list"}, &out))
+		assert.True(t, strings.HasPrefix(out.String(), "1.0.0"), out.String())
+		assert.Contains(t, out.String(), sha256Hex([]byte("content"))[:12])
+	})
+
+	t.Run("config takes precedence over the environment", func(t *testing.T) {
+		t.Setenv(artifactCacheEnv, "/from/env")
+		config := &Config{Cache: &CacheConfig{Dir: "/from/config"}}
+		assert.Equal(t, "/from/config", config.ArtifactCacheDir())
+	})
+
+	t.Run("prune requires --keep", func(t *testing.T) {
+		config := &Config{Cache: &CacheConfig{Dir: t.TempDir()}}
+		err := runCache(config, []string{"prune"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "--keep is required")
+	})
+
+	t.Run("verify reports corrupt entries", func(t *testing.T) {
+		dir := t.TempDir()
+		storeVersions(t, &artifactCache{dir: dir, logger: NewLogger()}, map[string]string{"1.0.0": "content"})
+		tamperObject(t, filepath.Join(dir, "sha256", sha256Hex([]byte("content"))+".jar"), "x")
+
+		err := runCache(&Config{Cache: &CacheConfig{Dir: dir}}, []string{"verify"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "1.0.0")
+	})
+}
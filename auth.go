@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// redactedValue replaces secrets in logged URLs.
+const redactedValue = "xxxxx"
+
+// sensitiveQueryParams lists query parameters whose values are never logged.
+var sensitiveQueryParams = []string{"token", "access_token", "apikey", "api_key", "password", "signature", "x-amz-signature"}
+
+// authTransport adds repository credentials to requests for the repository host.
+// Requests to other hosts, such as redirects to a CDN, are sent without them.
+type authTransport struct {
+	base  http.RoundTripper
+	host  string
+	apply func(req *http.Request)
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.EqualFold(req.URL.Host, t.host) {
+		return t.base.RoundTrip(req)
+	}
+
+	authenticated := req.Clone(req.Context())
+	t.apply(authenticated)
+	return t.base.RoundTrip(authenticated)
+}
+
+// newAuthTransport wraps base with the configured credentials for baseURL.
+// It returns base unchanged when no credentials apply.
+func newAuthTransport(base http.RoundTripper, auth *AuthConfig, baseURL string, logger *Logger) (http.RoundTripper, error) {
+	if auth == nil {
+		return base, nil
+	}
+
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	apply, description, err := auth.credentials(parsed.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	if apply == nil {
+		return base, nil
+	}
+
+	logger.Info("Using %s for %s", description, parsed.Host)
+	return &authTransport{base: base, host: parsed.Host, apply: apply}, nil
+}
+
+// credentials returns a function that adds credentials to a request, and a
+// description for logging that never contains the secret itself.
+// Explicit settings take precedence over ~/.netrc.
+func (a *AuthConfig) credentials(host string) (func(req *http.Request), string, error) {
+	switch {
+	case a.Token != "":
+		header := a.TokenHeader
+		if header == "" || strings.EqualFold(header, "Authorization") {
+			token := a.Token
+			return func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}, "bearer token", nil
+		}
+		token := a.Token
+		return func(req *http.Request) {
+			req.Header.Set(header, token)
+		}, fmt.Sprintf("token in %s header", header), nil
+
+	case a.Username != "":
+		username, password := a.Username, a.Password
+		return func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}, fmt.Sprintf("basic auth as %s", username), nil
+
+	case a.Netrc:
+		path := netrcPath()
+		username, password, found, err := lookupNetrc(path, host)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !found {
+			return nil, "", nil
+		}
+		return func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}, fmt.Sprintf("credentials for %s from %s", username, path), nil
+	}
+
+	return nil, "", nil
+}
+
+// netrcPath returns the netrc file location, honoring $NETRC like curl and git.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(homeDir, name)
+}
+
+// lookupNetrc finds the login and password for host in a netrc file.
+// A missing file is not an error. The "default" entry is used when no
+// machine entry matches.
+func lookupNetrc(path, host string) (string, string, bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	defer file.Close()
+
+	type entry struct {
+		login, password string
+	}
+	var machine, fallback *entry
+	var current *entry
+	inMacro := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Macro definitions run until the next blank line
+		if inMacro {
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for n := 0; n < len(fields); n++ {
+			next := func() string {
+				if n+1 < len(fields) {
+					n++
+					return fields[n]
+				}
+				return ""
+			}
+
+			switch fields[n] {
+			case "machine":
+				current = nil
+				if name := next(); strings.EqualFold(name, host) && machine == nil {
+					machine = &entry{}
+					current = machine
+				}
+			case "default":
+				current = nil
+				if fallback == nil {
+					fallback = &entry{}
+					current = fallback
+				}
+			case "login":
+				if value := next(); current != nil {
+					current.login = value
+				}
+			case "password":
+				if value := next(); current != nil {
+					current.password = value
+				}
+			case "account":
+				next()
+			case "macdef":
+				inMacro = true
+				n = len(fields)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", false, err
+	}
+
+	if machine == nil {
+		machine = fallback
+	}
+	if machine == nil || machine.login == "" {
+		return "", "", false, nil
+	}
+	return machine.login, machine.password, true, nil
+}
+
+// redactURL removes credentials from a URL so it can be logged safely.
+// User info passwords and sensitive query parameters are replaced.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		changed := false
+		for key := range query {
+			for _, sensitive := range sensitiveQueryParams {
+				if strings.EqualFold(key, sensitive) {
+					query.Set(key, redactedValue)
+					changed = true
+				}
+			}
+		}
+		if changed {
+			parsed.RawQuery = query.Encode()
+		}
+	}
+
+	return parsed.Redacted()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Checksum verification modes for download.checksum.
+const (
+	ChecksumRequired = "required"
+	ChecksumOptional = "optional"
+	ChecksumOff      = "off"
+)
+
+// errInvalidChecksum marks a sidecar that exists but does not contain a usable digest.
+var errInvalidChecksum = errors.New("invalid checksum file")
+
+// checksumAlgorithm describes a Maven checksum sidecar file.
+type checksumAlgorithm struct {
+	name    string
+	newHash func() hash.Hash
+}
+
+// checksumAlgorithms lists the supported sidecars, strongest first.
+var checksumAlgorithms = []checksumAlgorithm{
+	{name: "sha512", newHash: sha512.New},
+	{name: "sha256", newHash: sha256.New},
+	{name: "sha1", newHash: sha1.New},
+	{name: "md5", newHash: md5.New},
+}
+
+// expectedChecksum is a checksum published next to an artifact.
+type expectedChecksum struct {
+	algorithm checksumAlgorithm
+	value     string
+	source    string
+}
+
+// newHash returns a fresh hash for the checksum's algorithm.
+func (c *expectedChecksum) newHash() hash.Hash {
+	return c.algorithm.newHash()
+}
+
+// verify compares the computed digest against the expected value.
+func (c *expectedChecksum) verify(h hash.Hash) error {
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != c.value {
+		return fmt.Errorf("%s checksum mismatch: expected %s, got %s", c.algorithm.name, c.value, actual)
+	}
+	return nil
+}
+
+// fetchChecksum downloads the strongest checksum sidecar available for the artifact.
+// It returns nil when no sidecar exists and checksums are not required.
+func (i *Installer) fetchChecksum(client *http.Client, artifactURL string) (*expectedChecksum, error) {
+	mode := i.config.Download.ChecksumMode()
+	if mode == ChecksumOff {
+		i.logger.Warning("Checksum verification is disabled")
+		return nil, nil
+	}
+
+	for _, algorithm := range checksumAlgorithms {
+		checksumURL := artifactURL + "." + algorithm.name
+
+		value, found, err := fetchChecksumFile(client, checksumURL, algorithm)
+		if errors.Is(err, errInvalidChecksum) && mode == ChecksumOptional {
+			i.logger.Warning("Ignoring %v", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		i.logger.Info("Using %s checksum from %s", algorithm.name, redactURL(checksumURL))
+		return &expectedChecksum{algorithm: algorithm, value: value, source: checksumURL}, nil
+	}
+
+	if mode == ChecksumRequired {
+		return nil, fmt.Errorf("no checksum file found for %s", redactURL(artifactURL))
+	}
+
+	i.logger.Warning("No checksum file found, skipping verification")
+	return nil, nil
+}
+
+// fetchChecksumFile retrieves and parses a single checksum sidecar.
+// A missing file is reported with found == false rather than an error.
+func fetchChecksumFile(client *http.Client, checksumURL string, algorithm checksumAlgorithm) (string, bool, error) {
+	resp, err := client.Get(checksumURL)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch checksum: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("failed to fetch checksum %s: %s", redactURL(checksumURL), resp.Status)
+	}
+
+	// Checksum files are tiny; guard against misconfigured servers returning HTML pages.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	value, err := parseChecksum(body, algorithm)
+	if err != nil {
+		return "", false, fmt.Errorf("%w %s: %v", errInvalidChecksum, redactURL(checksumURL), err)
+	}
+
+	return value, true, nil
+}
+
+// parseChecksum extracts the hex digest from a checksum file.
+// Maven sidecars contain just the digest, but "<digest>  <filename>" is accepted too.
+func parseChecksum(data []byte, algorithm checksumAlgorithm) (string, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum")
+	}
+
+	value := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(value); err != nil {
+		return "", fmt.Errorf("checksum is not hexadecimal")
+	}
+
+	if expectedLen := algorithm.newHash().Size() * 2; len(value) != expectedLen {
+		return "", fmt.Errorf("expected %d hex characters for %s, got %d", expectedLen, algorithm.name, len(value))
+	}
+
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksum(t *testing.T) {
+	sha256Algorithm := checksumAlgorithms[1]
+	digest := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "plain digest", input: digest, expected: digest},
+		{name: "digest with trailing newline", input: digest + "\n", expected: digest},
+		{name: "digest with file name", input: digest + "  moderne-cli-1.0.0.jar\n", expected: digest},
+		{name: "uppercase digest", input: strings.ToUpper(digest), expected: digest},
+		{name: "empty file", input: "", wantErr: true},
+		{name: "not hexadecimal", input: strings.Repeat("zz", 32), wantErr: true},
+		{name: "wrong length", input: "abcd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseChecksum([]byte(tt.input), sha256Algorithm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestFetchChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("content"))
+	sha256Hex := hex.EncodeToString(sum[:])
+
+	newInstaller := func(mode string) *Installer {
+		return &Installer{
+			config: &Config{Download: DownloadConfig{Checksum: mode}},
+			logger: NewLogger(),
+		}
+	}
+
+	t.Run("prefers strongest available sidecar", func(t *testing.T) {
+		var requested []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			if r.URL.Path == "/artifact.jar.sha256" {
+				w.Write([]byte(sha256Hex))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		expected, err := newInstaller(ChecksumRequired).fetchChecksum(http.DefaultClient, server.URL+"/artifact.jar")
+		require.NoError(t, err)
+		require.NotNil(t, expected)
+		assert.Equal(t, "sha256", expected.algorithm.name)
+		assert.Equal(t, sha256Hex, expected.value)
+		assert.Equal(t, []string{"/artifact.jar.sha512", "/artifact.jar.sha256"}, requested)
+	})
+
+	t.Run("returns nil when optional and no sidecar exists", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		expected, err := newInstaller(ChecksumOptional).fetchChecksum(http.DefaultClient, server.URL+"/artifact.jar")
+		require.NoError(t, err)
+		assert.Nil(t, expected)
+	})
+
+	t.Run("returns error when required and no sidecar exists", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := newInstaller(ChecksumRequired).fetchChecksum(http.DefaultClient, server.URL+"/artifact.jar")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no checksum file found")
+	})
+
+	t.Run("does not contact server when off", func(t *testing.T) {
+		serverCalled := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverCalled = true
+		}))
+		defer server.Close()
+
+		expected, err := newInstaller(ChecksumOff).fetchChecksum(http.DefaultClient, server.URL+"/artifact.jar")
+		require.NoError(t, err)
+		assert.Nil(t, expected)
+		assert.False(t, serverCalled, "server should not have been called")
+	})
+}
+
+func TestExpectedChecksumVerify(t *testing.T) {
+	sum := sha256.Sum256([]byte("content"))
+	expected := &expectedChecksum{algorithm: checksumAlgorithms[1], value: hex.EncodeToString(sum[:])}
+
+	t.Run("accepts matching content", func(t *testing.T) {
+		h := expected.newHash()
+		h.Write([]byte("content"))
+		assert.NoError(t, expected.verify(h))
+	})
+
+	t.Run("rejects different content", func(t *testing.T) {
+		h := expected.newHash()
+		h.Write([]byte("tampered"))
+		err := expected.verify(h)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "sha256 checksum mismatch")
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
+// newHTTPClient creates the HTTP client used for every request the installer makes.
+// Requests carry the repository credentials, go through the configured proxy,
+// and are retried according to the retry policy. file:// URLs are read from disk.
+func newHTTPClient(download *DownloadConfig, logger *Logger) (*http.Client, error) {
+	transport, err := newTransport(download, logger)
+	if err != nil {
+		return nil, err
+	}
+	transport = &fileTransport{base: transport}
+
+	transport, err = newAuthTransport(transport, download.Auth, download.BaseURL, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{
+			base:   transport,
+			policy: download.RetryPolicy(),
+			logger: logger,
+			sleep:  time.Sleep,
+		},
+	}, nil
+}
+
+// newTransport returns the base transport with proxy settings from config.yaml
+// and the environment, and the configured TLS settings, applied.
+func newTransport(download *DownloadConfig, logger *Logger) (http.RoundTripper, error) {
+	tlsConfig, err := newTLSConfig(download.TLS, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	pacClient := newPACClient(tlsConfig, download.RetryPolicy(), logger)
+	settings, err := resolveProxySettings(download.Proxy, os.Getenv, pacClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings == nil && tlsConfig == nil {
+		return http.DefaultTransport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if settings != nil {
+		logger.Info("Using proxy: %s", settings.describe())
+		transport.Proxy = settings.proxyFor
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("uses default transport when no proxy configured", func(t *testing.T) {
+		clearProxyEnv(t)
+		download := &DownloadConfig{
+			BaseURL: "http://example.com",
+		}
+
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+		require.IsType(t, &retryTransport{}, client.Transport)
+		require.IsType(t, &fileTransport{}, client.Transport.(*retryTransport).base)
+		assert.Equal(t, http.DefaultTransport, client.Transport.(*retryTransport).base.(*fileTransport).base)
+	})
+
+	t.Run("returns custom client with proxy", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: "http://example.com",
+			Proxy: &ProxyConfig{
+				URL: "http://proxy:8080",
+			},
+		}
+
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+		require.IsType(t, &retryTransport{}, client.Transport)
+		require.IsType(t, &fileTransport{}, client.Transport.(*retryTransport).base)
+		assert.NotEqual(t, http.DefaultTransport, client.Transport.(*retryTransport).base.(*fileTransport).base)
+	})
+
+	t.Run("returns error for invalid proxy URL", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: "http://example.com",
+			Proxy: &ProxyConfig{
+				URL: "://invalid-url",
+			},
+		}
+
+		_, err := newHTTPClient(download, NewLogger())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid proxy URL")
+	})
+}
+
+// clearProxyEnv unsets proxy environment variables for the duration of a test.
+func clearProxyEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+}
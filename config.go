@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,12 +15,41 @@ const configFileName = "config.yaml"
 // Config holds the application configuration.
 type Config struct {
 	Download DownloadConfig `yaml:"download"`
+	Cache    *CacheConfig   `yaml:"cache,omitempty"`
 }
 
 // DownloadConfig holds download-related settings.
 type DownloadConfig struct {
-	BaseURL string       `yaml:"baseUrl"`
-	Proxy   *ProxyConfig `yaml:"proxy,omitempty"`
+	BaseURL        string         `yaml:"baseUrl"`
+	RepositoryType string         `yaml:"repositoryType,omitempty"`
+	Mirrors        []MirrorConfig `yaml:"mirrors,omitempty"`
+	Auth           *AuthConfig    `yaml:"auth,omitempty"`
+	Proxy          *ProxyConfig   `yaml:"proxy,omitempty"`
+	TLS            *TLSConfig     `yaml:"tls,omitempty"`
+	Checksum       string         `yaml:"checksum,omitempty"`
+	Signature      string         `yaml:"signature,omitempty"`
+	SigningKey     string         `yaml:"signingKey,omitempty"`
+	Retry          *RetryConfig   `yaml:"retry,omitempty"`
+	Channel        string         `yaml:"channel,omitempty"`
+}
+
+// MirrorConfig holds a download source tried in order with the other mirrors.
+type MirrorConfig struct {
+	Name           string       `yaml:"name,omitempty"`
+	BaseURL        string       `yaml:"baseUrl"`
+	RepositoryType string       `yaml:"repositoryType,omitempty"`
+	Auth           *AuthConfig  `yaml:"auth,omitempty"`
+	Proxy          *ProxyConfig `yaml:"proxy,omitempty"`
+}
+
+// AuthConfig holds repository credentials.
+// Token takes precedence over username/password, which take precedence over netrc.
+type AuthConfig struct {
+	Username    string `yaml:"username,omitempty"`
+	Password    string `yaml:"password,omitempty"`
+	Token       string `yaml:"token,omitempty"`
+	TokenHeader string `yaml:"tokenHeader,omitempty"`
+	Netrc       bool   `yaml:"netrc,omitempty"`
 }
 
 // ProxyConfig holds proxy settings.
@@ -26,18 +58,172 @@ type ProxyConfig struct {
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
 	NoProxy  string `yaml:"noProxy,omitempty"`
+	PacURL   string `yaml:"pacUrl,omitempty"`
+	PacFile  string `yaml:"pacFile,omitempty"`
+}
+
+// TLSConfig holds TLS settings for repositories and proxies.
+type TLSConfig struct {
+	CAFile             string `yaml:"caFile,omitempty"`
+	CAPem              string `yaml:"caPem,omitempty"`
+	CertFile           string `yaml:"certFile,omitempty"`
+	KeyFile            string `yaml:"keyFile,omitempty"`
+	MinVersion         string `yaml:"minVersion,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+}
+
+// CacheConfig holds local cache settings.
+type CacheConfig struct {
+	Dir         string         `yaml:"dir,omitempty"`
+	MetadataTTL *time.Duration `yaml:"metadataTtl,omitempty"`
+}
+
+// defaultMetadataTTL is how long cached maven-metadata.xml is used without revalidation.
+const defaultMetadataTTL = 10 * time.Minute
+
+// MetadataTTL returns how long cached metadata is fresh. Zero revalidates on every run.
+func (c *Config) MetadataTTL() time.Duration {
+	if c.Cache == nil || c.Cache.MetadataTTL == nil {
+		return defaultMetadataTTL
+	}
+	return max(*c.Cache.MetadataTTL, 0)
+}
+
+// ArtifactCacheDir returns the shared artifact cache directory from cache.dir,
+// falling back to $MODERNE_INSTALLER_CACHE. Empty means no shared cache.
+func (c *Config) ArtifactCacheDir() string {
+	if c.Cache != nil && c.Cache.Dir != "" {
+		return c.Cache.Dir
+	}
+	return os.Getenv(artifactCacheEnv)
+}
+
+// RetryConfig holds retry settings for HTTP requests.
+type RetryConfig struct {
+	Attempts  int           `yaml:"attempts,omitempty"`
+	BaseDelay time.Duration `yaml:"baseDelay,omitempty"`
+	MaxDelay  time.Duration `yaml:"maxDelay,omitempty"`
+	Jitter    float64       `yaml:"jitter,omitempty"`
 }
 
 // HasProxy returns true if proxy configuration is provided.
 func (d *DownloadConfig) HasProxy() bool {
-	return d.Proxy != nil && d.Proxy.URL != ""
+	return d.Proxy != nil && (d.Proxy.URL != "" || d.Proxy.PacURL != "" || d.Proxy.PacFile != "")
+}
+
+// MirrorList returns the mirrors to download from, in order.
+// Without download.mirrors, baseUrl is the only mirror. Mirrors without their
+// own proxy or auth settings inherit download.proxy and download.auth.
+func (d *DownloadConfig) MirrorList() []MirrorConfig {
+	if len(d.Mirrors) == 0 {
+		return []MirrorConfig{{Name: "default", BaseURL: d.BaseURL, RepositoryType: d.RepositoryType, Auth: d.Auth, Proxy: d.Proxy}}
+	}
+
+	mirrors := make([]MirrorConfig, 0, len(d.Mirrors))
+	for n, m := range d.Mirrors {
+		if m.Name == "" {
+			m.Name = fmt.Sprintf("mirror-%d", n+1)
+		}
+		if m.RepositoryType == "" {
+			m.RepositoryType = d.RepositoryType
+		}
+		if m.Auth == nil {
+			m.Auth = d.Auth
+		}
+		if m.Proxy == nil {
+			m.Proxy = d.Proxy
+		}
+		mirrors = append(mirrors, m)
+	}
+	return mirrors
+}
+
+// ChecksumMode returns the effective checksum verification mode.
+// Empty defaults to optional; unrecognized values are treated as required.
+func (d *DownloadConfig) ChecksumMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(d.Checksum)); mode {
+	case "":
+		return ChecksumOptional
+	case ChecksumRequired, ChecksumOptional, ChecksumOff:
+		return mode
+	default:
+		return ChecksumRequired
+	}
+}
+
+// RepositoryTypeMode returns how a mirror's versions are looked up.
+// Empty and unrecognized values are treated as maven.
+func (m *MirrorConfig) RepositoryTypeMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(m.RepositoryType)); mode {
+	case RepositoryArtifactory, RepositoryNexus, RepositoryHTTP:
+		return mode
+	default:
+		return RepositoryMaven
+	}
+}
+
+// ChannelMode returns the effective release channel used to pick the latest version.
+// Empty and unrecognized values are treated as stable.
+func (d *DownloadConfig) ChannelMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(d.Channel)); mode {
+	case ChannelPrerelease, ChannelSnapshot:
+		return mode
+	default:
+		return ChannelStable
+	}
+}
+
+// SignatureMode returns the effective PGP signature verification mode.
+// Empty defaults to optional; unrecognized values are treated as required.
+func (d *DownloadConfig) SignatureMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(d.Signature)); mode {
+	case "":
+		return SignatureOptional
+	case SignatureRequired, SignatureOptional, SignatureOff:
+		return mode
+	default:
+		return SignatureRequired
+	}
+}
+
+// RetryPolicy returns the retry settings with defaults applied to unset values.
+func (d *DownloadConfig) RetryPolicy() RetryConfig {
+	policy := RetryConfig{
+		Attempts:  defaultRetryAttempts,
+		BaseDelay: defaultRetryBaseDelay,
+		MaxDelay:  defaultRetryMaxDelay,
+		Jitter:    defaultRetryJitter,
+	}
+	if d.Retry == nil {
+		return policy
+	}
+
+	if d.Retry.Attempts > 0 {
+		policy.Attempts = d.Retry.Attempts
+	}
+	if d.Retry.BaseDelay > 0 {
+		policy.BaseDelay = d.Retry.BaseDelay
+	}
+	if d.Retry.MaxDelay > 0 {
+		policy.MaxDelay = d.Retry.MaxDelay
+	}
+	if d.Retry.Jitter > 0 {
+		policy.Jitter = d.Retry.Jitter
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+
+	return policy
 }
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
 		Download: DownloadConfig{
-			BaseURL: DefaultBaseURL,
+			BaseURL:   DefaultBaseURL,
+			Checksum:  ChecksumOptional,
+			Signature: SignatureOptional,
 		},
 	}
 }
@@ -86,7 +272,37 @@ func mergeConfig(base, loaded *Config) {
 	if loaded.Download.BaseURL != "" {
 		base.Download.BaseURL = loaded.Download.BaseURL
 	}
+	if len(loaded.Download.Mirrors) > 0 {
+		base.Download.Mirrors = loaded.Download.Mirrors
+	}
+	if loaded.Download.Auth != nil {
+		base.Download.Auth = loaded.Download.Auth
+	}
 	if loaded.Download.Proxy != nil {
 		base.Download.Proxy = loaded.Download.Proxy
 	}
+	if loaded.Download.TLS != nil {
+		base.Download.TLS = loaded.Download.TLS
+	}
+	if loaded.Download.Checksum != "" {
+		base.Download.Checksum = loaded.Download.Checksum
+	}
+	if loaded.Download.Signature != "" {
+		base.Download.Signature = loaded.Download.Signature
+	}
+	if loaded.Download.SigningKey != "" {
+		base.Download.SigningKey = loaded.Download.SigningKey
+	}
+	if loaded.Download.Retry != nil {
+		base.Download.Retry = loaded.Download.Retry
+	}
+	if loaded.Download.Channel != "" {
+		base.Download.Channel = loaded.Download.Channel
+	}
+	if loaded.Download.RepositoryType != "" {
+		base.Download.RepositoryType = loaded.Download.RepositoryType
+	}
+	if loaded.Cache != nil {
+		base.Cache = loaded.Cache
+	}
 }
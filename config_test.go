@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +15,47 @@ func TestDefaultConfig(t *testing.T) {
 
 	assert.Equal(t, DefaultBaseURL, config.Download.BaseURL)
 	assert.Nil(t, config.Download.Proxy)
+	assert.Equal(t, ChecksumOptional, config.Download.Checksum)
+	assert.Equal(t, SignatureOptional, config.Download.Signature)
+}
+
+func TestDownloadConfig_ChecksumMode(t *testing.T) {
+	tests := []struct {
+		checksum string
+		expected string
+	}{
+		{checksum: "", expected: ChecksumOptional},
+		{checksum: "required", expected: ChecksumRequired},
+		{checksum: "Optional", expected: ChecksumOptional},
+		{checksum: "off", expected: ChecksumOff},
+		{checksum: "bogus", expected: ChecksumRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.checksum, func(t *testing.T) {
+			config := DownloadConfig{Checksum: tt.checksum}
+			assert.Equal(t, tt.expected, config.ChecksumMode())
+		})
+	}
+}
+
+func TestDownloadConfig_ChannelMode(t *testing.T) {
+	tests := []struct {
+		channel  string
+		expected string
+	}{
+		{channel: "", expected: ChannelStable},
+		{channel: "Prerelease", expected: ChannelPrerelease},
+		{channel: "snapshot", expected: ChannelSnapshot},
+		{channel: "bogus", expected: ChannelStable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			config := DownloadConfig{Channel: tt.channel}
+			assert.Equal(t, tt.expected, config.ChannelMode())
+		})
+	}
 }
 
 func TestDownloadConfig_HasProxy(t *testing.T) {
@@ -137,6 +179,16 @@ func TestLoadConfigFile(t *testing.T) {
 		assert.Equal(t, "localhost,127.0.0.1", config.Download.Proxy.NoProxy)
 	})
 
+	t.Run("loads metadata cache TTL", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("cache:\n  metadataTtl: 0s\n"), 0644))
+
+		config, err := loadConfigFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), config.MetadataTTL())
+		assert.Equal(t, defaultMetadataTTL, DefaultConfig().MetadataTTL())
+	})
+
 	t.Run("returns error for non-existent file", func(t *testing.T) {
 		_, err := loadConfigFile("/non/existent/path/config.yaml")
 		assert.Error(t, err)
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// versionConstraint is a version expression such as "3.57", "3.x", "~3.57.0",
+// "^3.0" or ">=3.50 <4". Space-separated comparators must all match; "||"
+// separates alternatives.
+type versionConstraint struct {
+	expression   string
+	alternatives [][]comparator
+}
+
+// comparator is a single bound such as ">=3.50.0".
+type comparator struct {
+	op      string
+	version semver
+}
+
+// constraintOperators lists comparison operators, longest first so ">=" wins over ">".
+var constraintOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// isVersionConstraint reports whether a -version value needs resolving against
+// the available versions. A complete version like 3.57.9 is used as given.
+func isVersionConstraint(expression string) bool {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return false
+	}
+	parsed, ok := parseSemver(expression)
+	return !ok || (len(parsed.parts) < 3 && parsed.prerelease == "")
+}
+
+// parseConstraint parses a version constraint expression.
+func parseConstraint(expression string) (*versionConstraint, error) {
+	constraint := &versionConstraint{expression: expression}
+
+	for _, alternative := range strings.Split(expression, "||") {
+		var comparators []comparator
+		fields := strings.Fields(alternative)
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// Allow a space between operator and version: ">= 3.50"
+			if isOperator(field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+
+			parsed, err := parseComparators(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", expression, err)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q: empty expression", expression)
+		}
+		constraint.alternatives = append(constraint.alternatives, comparators)
+	}
+
+	return constraint, nil
+}
+
+func isOperator(field string) bool {
+	for _, op := range constraintOperators {
+		if field == op {
+			return true
+		}
+	}
+	return field == "~" || field == "^"
+}
+
+// parseComparators expands one term into the bounds it stands for.
+func parseComparators(term string) ([]comparator, error) {
+	switch {
+	case strings.HasPrefix(term, "~"):
+		lower, specified, err := parsePartial(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		if specified == 0 {
+			return nil, nil
+		}
+		// ~3.57.0 and ~3.57 allow patch updates; ~3 allows minor updates
+		return bounded(lower, bump(lower, min(specified, 2)-1)), nil
+
+	case strings.HasPrefix(term, "^"):
+		lower, specified, err := parsePartial(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		if specified == 0 {
+			return nil, nil
+		}
+		// ^3.57.0 allows everything below 4.0.0; ^0.5.1 everything below 0.6.0
+		index := specified - 1
+		for i := 0; i < specified; i++ {
+			if lower.parts[i] != 0 {
+				index = i
+				break
+			}
+		}
+		return bounded(lower, bump(lower, index)), nil
+	}
+
+	op := ""
+	for _, candidate := range constraintOperators {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	version, specified, err := parsePartial(term[len(op):])
+	if err != nil {
+		return nil, err
+	}
+	if specified == 0 {
+		// "*", "x" or an operator against a wildcard match everything
+		return nil, nil
+	}
+
+	if specified >= 3 || version.prerelease != "" {
+		if op == "" {
+			op = "="
+		}
+		return []comparator{{op: op, version: version}}, nil
+	}
+
+	// A partial version covers a whole range: 3.57 means >=3.57.0 <3.58.0
+	upper := bump(version, specified-1)
+	switch op {
+	case "", "=":
+		return bounded(version, upper), nil
+	case "!=":
+		return nil, fmt.Errorf("%q cannot be used with a partial version", op)
+	case ">":
+		return []comparator{{op: ">=", version: upper}}, nil
+	case "<=":
+		return []comparator{{op: "<", version: upper}}, nil
+	}
+	return []comparator{{op: op, version: version}}, nil
+}
+
+// parsePartial parses a version whose trailing components may be missing or
+// wildcards. It returns how many components were given.
+func parsePartial(value string) (semver, int, error) {
+	value = strings.TrimSpace(value)
+	fields := strings.Split(value, ".")
+	specified := 0
+	for _, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		specified++
+	}
+	if specified < len(fields) {
+		for _, field := range fields[specified:] {
+			if field != "x" && field != "X" && field != "*" {
+				return semver{}, 0, fmt.Errorf("invalid version %q", value)
+			}
+		}
+		value = strings.Join(fields[:specified], ".")
+	}
+	if specified == 0 {
+		return semver{}, 0, nil
+	}
+
+	version, ok := parseSemver(value)
+	if !ok {
+		return semver{}, 0, fmt.Errorf("invalid version %q", value)
+	}
+	return version, len(version.parts), nil
+}
+
+// bump returns the lowest version above every version sharing the first
+// index+1 components with v, e.g. bump(3.57.9, 1) is 3.58.0.
+func bump(v semver, index int) semver {
+	parts := make([]int, index+1)
+	copy(parts, v.parts)
+	parts[index]++
+	return semver{parts: parts}
+}
+
+func bounded(lower, upper semver) []comparator {
+	return []comparator{{op: ">=", version: lower}, {op: "<", version: upper}}
+}
+
+// matches reports whether the version satisfies the constraint.
+func (c *versionConstraint) matches(version string) bool {
+	parsed, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+
+	for _, comparators := range c.alternatives {
+		matched := true
+		for _, comparator := range comparators {
+			if !comparator.matches(parsed) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparator) matches(v semver) bool {
+	result := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
+
+// resolve returns the highest version matching the constraint among those the
+// release channel allows. A constraint naming a prerelease explicitly may also
+// match prereleases.
+func (c *versionConstraint) resolve(available []string, channel string) (string, error) {
+	namesPrerelease := strings.Contains(c.expression, "-")
+
+	best := ""
+	for _, version := range available {
+		if !channelAllows(channel, version) && !namesPrerelease {
+			continue
+		}
+		if c.matches(version) && (best == "" || compareVersions(version, best) > 0) {
+			best = version
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no version matches %q", c.expression)
+	}
+	return best, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsVersionConstraint(t *testing.T) {
+	for _, exact := range []string{"", "3.57.9", "3.58.0-rc.1", "1.2.3.4"} {
+		assert.False(t, isVersionConstraint(exact), exact)
+	}
+	for _, constraint := range []string{"3", "3.57", "3.x", "~3.57.0", "^3.0.0", ">=3.50 <4", "=3.57.9", "*"} {
+		assert.True(t, isVersionConstraint(constraint), constraint)
+	}
+}
+
+func TestVersionConstraintResolve(t *testing.T) {
+	available := []string{"2.9.0", "3.49.2", "3.50.0", "3.56.1", "3.57.0", "3.57.9", "3.57.10", "3.58.0-rc.1", "3.58.0", "4.0.0"}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"3.57", "3.57.10"},
+		{"3.57.x", "3.57.10"},
+		{"3.x", "3.58.0"},
+		{"3", "3.58.0"},
+		{"*", "4.0.0"},
+		{"~3.57.0", "3.57.10"},
+		{"~3.57", "3.57.10"},
+		{"~3", "3.58.0"},
+		{"^3.50.0", "3.58.0"},
+		{">=3.50 <4", "3.58.0"},
+		{">= 3.50 < 3.57", "3.56.1"},
+		{">3.57 <4", "3.58.0"},
+		{"<=3.57", "3.57.10"},
+		{"=3.57.9", "3.57.9"},
+		{"<3.50 || 4.x", "4.0.0"},
+		{">=3.58.0-rc.1 <3.58.0", "3.58.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			constraint, err := parseConstraint(tt.expression)
+			require.NoError(t, err)
+
+			version, err := constraint.resolve(available, ChannelStable)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+
+	t.Run("skips prereleases unless requested", func(t *testing.T) {
+		constraint, err := parseConstraint("3.58")
+		require.NoError(t, err)
+
+		version, err := constraint.resolve([]string{"3.57.9", "3.58.0-rc.1"}, ChannelStable)
+		require.Error(t, err)
+		assert.Empty(t, version)
+		assert.Contains(t, err.Error(), `no version matches "3.58"`)
+	})
+
+	t.Run("includes prereleases in the prerelease channel", func(t *testing.T) {
+		constraint, err := parseConstraint("3.x")
+		require.NoError(t, err)
+
+		version, err := constraint.resolve([]string{"3.57.9", "3.58.0-rc.1"}, ChannelPrerelease)
+		require.NoError(t, err)
+		assert.Equal(t, "3.58.0-rc.1", version)
+	})
+
+	t.Run("rejects invalid expressions", func(t *testing.T) {
+		for _, expression := range []string{"abc", ">=", "3.x.1", "!=3.57", "||"} {
+			_, err := parseConstraint(expression)
+			assert.Error(t, err, expression)
+		}
+	})
+}
+
+func TestRepositoryResolveVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(syncMetadata))
+	}))
+	defer server.Close()
+
+	repository, err := NewRepository(&DownloadConfig{BaseURL: server.URL}, NewLogger())
+	require.NoError(t, err)
+
+	version, err := repository.ResolveVersion("~3.57.0")
+	require.NoError(t, err)
+	assert.Equal(t, "3.57.10", version)
+}
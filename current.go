@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// currentFileName holds the active version; launchers on Windows read it.
+	currentFileName = "current"
+	// currentJARName is a symlink to the active JAR on Unix, used by the alias.
+	currentJARName = "moderne-cli.jar"
+)
+
+// currentJARPath returns the stable path the shell alias runs.
+func (i *Installer) currentJARPath() string {
+	return filepath.Join(i.binDir, currentJARName)
+}
+
+// setCurrent makes the installer's version the active one. The state file is
+// always written; on Unix the moderne-cli.jar symlink is switched atomically.
+func (i *Installer) setCurrent() error {
+	if err := writeFileAtomic(filepath.Join(i.binDir, currentFileName), []byte(i.version+"\n")); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		// Relative target, so the install directory can be moved or mounted elsewhere
+		tmp := i.currentJARPath() + partFileSuffix
+		os.Remove(tmp)
+		if err := os.Symlink(i.jarFileName, tmp); err != nil {
+			return fmt.Errorf("failed to link %s: %w", currentJARName, err)
+		}
+		if err := os.Rename(tmp, i.currentJARPath()); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to link %s: %w", currentJARName, err)
+		}
+	}
+
+	i.logger.Success("Active version: %s", i.version)
+	return nil
+}
+
+// readCurrent returns the active version in binDir, or "" if none is set.
+func readCurrent(binDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(binDir, currentFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// runUse implements the use subcommand: switch the active version to one that
+// is already installed, without downloading anything.
+func runUse(config *Config, args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return fmt.Errorf("usage: use <version>")
+	}
+
+	installer := NewInstallerWithConfig(args[0], config, nil)
+	if _, err := os.Stat(installer.jarPath); err != nil {
+		return fmt.Errorf("version %s is not installed; run the installer with -version %s first", args[0], args[0])
+	}
+
+	if err := installer.setCurrent(); err != nil {
+		return err
+	}
+	// Rewrite the alias as well, in case it still points at a versioned JAR
+	return installer.configureShellAlias()
+}
+
+// runList implements the list subcommand: print installed versions, marking
+// the active one.
+func runList(binDir string, out io.Writer) error {
+	versions, err := installedVersions(binDir)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(out, "No versions installed in %s\n", binDir)
+		return nil
+	}
+
+	current, err := readCurrent(binDir)
+	if err != nil {
+		return err
+	}
+	for n := len(versions) - 1; n >= 0; n-- {
+		marker := " "
+		if versions[n] == current {
+			marker = "*"
+		}
+		fmt.Fprintf(out, "%s %s\n", marker, versions[n])
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCurrent(t *testing.T) {
+	binDir := t.TempDir()
+	for _, version := range []string{"3.57.8", "3.57.9"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, jarFilePrefix+version+jarFileSuffix), []byte(version), 0644))
+	}
+
+	for _, version := range []string{"3.57.9", "3.57.8"} {
+		installer := &Installer{version: version, binDir: binDir, jarFileName: jarFilePrefix + version + jarFileSuffix, logger: NewLogger()}
+		require.NoError(t, installer.setCurrent())
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, version, current)
+
+		if runtime.GOOS != "windows" {
+			content, err := os.ReadFile(installer.currentJARPath())
+			require.NoError(t, err)
+			assert.Equal(t, version, string(content))
+		}
+	}
+
+	// The pointer is not itself an installed version
+	versions, err := installedVersions(binDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.57.8", "3.57.9"}, versions)
+}
+
+func TestCreateBatchFile(t *testing.T) {
+	installer := &Installer{binDir: t.TempDir(), logger: NewLogger()}
+	require.NoError(t, installer.createBatchFile())
+
+	content, err := os.ReadFile(filepath.Join(installer.binDir, batchFileName))
+	require.NoError(t, err)
+	// setlocal keeps MODERNE_CLI_VERSION out of the caller's CMD session
+	assert.True(t, strings.HasPrefix(string(content), "@echo off\nsetlocal\nset /p MODERNE_CLI_VERSION="), string(content))
+	assert.Contains(t, string(content), currentFileName)
+}
+
+func TestReadCurrentUnset(t *testing.T) {
+	current, err := readCurrent(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, current)
+}
+
+func TestRunList(t *testing.T) {
+	binDir := t.TempDir()
+
+	var out bytes.Buffer
+	require.NoError(t, runList(binDir, &out))
+	assert.Equal(t, "No versions installed in "+binDir+"\n", out.String())
+
+	for _, name := range []string{"moderne-cli-3.57.10.jar", "moderne-cli-3.57.9.jar"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), nil, 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, currentFileName), []byte("3.57.9\n"), 0644))
+
+	out.Reset()
+	require.NoError(t, runList(binDir, &out))
+	assert.Equal(t, "  3.57.10\n* 3.57.9\n", out.String())
+}
+
+func TestRunUse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("alias configuration is tested on Unix")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	binDir := filepath.Join(home, installDirName, binDirName)
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-3.57.8.jar"), []byte("3.57.8"), 0644))
+
+	t.Run("switches to an installed version", func(t *testing.T) {
+		require.NoError(t, runUse(DefaultConfig(), []string{"3.57.8"}))
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.8", current)
+
+		bashrc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+		assert.Contains(t, string(bashrc), `alias mod="java -jar `+filepath.Join(binDir, currentJARName)+`"`)
+	})
+
+	t.Run("rejects a version that is not installed", func(t *testing.T) {
+		err := runUse(DefaultConfig(), []string{"3.57.9"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version 3.57.9 is not installed")
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.8", current)
+	})
+
+	t.Run("requires a version", func(t *testing.T) {
+		assert.Error(t, runUse(DefaultConfig(), nil))
+	})
+}
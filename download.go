@@ -1,58 +1,236 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 // downloadJAR downloads the Moderne CLI JAR file.
 func (i *Installer) downloadJAR() error {
 	i.logger.Step("Downloading Moderne CLI JAR")
 
-	// Check if JAR already exists
-	if _, err := os.Stat(i.jarPath); err == nil {
+	// Check if JAR already exists. Downloads are renamed into place only after
+	// verification, so an existing file is always a complete install. Snapshots
+	// are checked for a newer build instead.
+	if _, err := os.Stat(i.jarPath); err == nil && (!isSnapshot(i.version) || i.localJAR != "") {
 		i.logger.Info("JAR file already exists at %s, skipping download", i.jarPath)
 		return nil
 	}
 
+	if i.localJAR != "" {
+		return i.installLocalJAR()
+	}
+
+	if i.repository == nil {
+		repository, err := NewRepository(&i.config.Download, i.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		i.repository = repository
+	}
+
+	return i.repository.try(i.downloadFromMirror)
+}
+
+// downloadFromMirror downloads and verifies the JAR from a single mirror.
+func (i *Installer) downloadFromMirror(m *mirror) error {
+	if isSnapshot(i.version) {
+		return i.downloadSnapshot(m)
+	}
+
 	// Construct download URL (Maven Central format: baseURL/version/moderne-cli-version.jar)
-	downloadURL := fmt.Sprintf("%s/%s/%s", i.config.Download.BaseURL, i.version, i.jarFileName)
-	i.logger.Info("Downloading from: %s", downloadURL)
+	downloadURL := fmt.Sprintf("%s/%s/%s", m.baseURL, i.version, i.jarFileName)
+	i.logger.Info("Downloading from: %s", redactURL(downloadURL))
+
+	return i.fetchArtifact(m.client, downloadURL)
+}
 
-	// Create HTTP client with optional proxy
-	client, err := i.createHTTPClient()
+// downloadSnapshot downloads the newest timestamped build of a -SNAPSHOT version.
+// The JAR keeps its -SNAPSHOT file name; the build it came from is recorded next
+// to it so a later run only downloads when a newer build has been published.
+func (i *Installer) downloadSnapshot(m *mirror) error {
+	unique, err := resolveSnapshotVersion(m, i.version)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP client: %w", err)
+		return err
+	}
+	if unique != i.version {
+		i.logger.Info("Resolved %s to build %s", i.version, unique)
 	}
 
-	resp, err := client.Get(downloadURL)
+	if _, err := os.Stat(i.jarPath); err == nil && installedSnapshot(i.jarPath) == unique {
+		i.logger.Info("Snapshot build %s is already installed, skipping download", unique)
+		return nil
+	}
+
+	downloadURL := fmt.Sprintf("%s/%s/%s%s%s", m.baseURL, i.version, jarFilePrefix, unique, jarFileSuffix)
+	i.logger.Info("Downloading from: %s", redactURL(downloadURL))
+
+	if err := i.fetchArtifact(m.client, downloadURL); err != nil {
+		return err
+	}
+	if err := recordSnapshot(i.jarPath, unique); err != nil {
+		i.logger.Warning("Could not record snapshot build: %v", err)
+	}
+	return nil
+}
+
+// installLocalJAR installs a pre-downloaded JAR given with -jar. Checksum and
+// signature files next to it are verified just like for a download.
+func (i *Installer) installLocalJAR() error {
+	artifactURL, err := fileURL(i.localJAR)
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		return fmt.Errorf("invalid JAR path: %w", err)
+	}
+	if _, err := os.Stat(i.localJAR); err != nil {
+		return fmt.Errorf("local JAR not found: %w", err)
+	}
+	i.logger.Info("Installing from local file: %s", i.localJAR)
+
+	return i.fetchArtifact(newFileClient(), artifactURL)
+}
+
+// fetchArtifact downloads the JAR from downloadURL, verifies it, and moves it into place.
+func (i *Installer) fetchArtifact(client *http.Client, downloadURL string) error {
+	expected, err := i.fetchChecksum(client, downloadURL)
+	if err != nil {
+		return err
+	}
+
+	// Snapshots change under the same version, so they bypass the shared cache
+	if i.cache != nil && !isSnapshot(i.version) {
+		if cached, ok := i.cache.lookup(i.version, expected); ok {
+			return i.installFromCache(client, downloadURL, cached)
+		}
+	}
+
+	// Download to a temporary file next to the JAR and rename it once verified.
+	// A partial file left by an interrupted attempt is resumed where possible.
+	partPath := i.jarPath + partFileSuffix
+	policy := i.config.Download.RetryPolicy()
+
+	var hasher hash.Hash
+	var size int64
+	for attempt := 1; ; attempt++ {
+		hasher, size, err = i.transferJAR(client, downloadURL, partPath, expected)
+		if err == nil {
+			break
+		}
+		var interrupted *interruptedError
+		if !errors.As(err, &interrupted) || attempt >= policy.Attempts {
+			if errors.As(err, &interrupted) {
+				// Keep the partial file so the next run can resume it
+				i.logger.Warning("Download interrupted, re-run the installer to resume")
+			}
+			return err
+		}
+
+		delay := policy.backoff(attempt, 0)
+		i.logger.Warning("Download interrupted (%v), resuming in %s (attempt %d/%d)", interrupted.err, delay, attempt+1, policy.Attempts)
+		time.Sleep(delay)
+	}
+
+	if expected != nil {
+		if err := expected.verify(hasher); err != nil {
+			removePartFile(partPath)
+			return &verificationError{err: err}
+		}
+		i.logger.Success("Verified %s checksum", expected.algorithm.name)
+	}
+
+	if err := i.verifySignature(client, downloadURL, partPath); err != nil {
+		removePartFile(partPath)
+		return &verificationError{err: err}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
+	if err := os.Rename(partPath, i.jarPath); err != nil {
+		removePartFile(partPath)
+		return fmt.Errorf("failed to move download into place: %w", err)
 	}
+	os.Remove(partPath + validatorFileSuffix)
 
-	out, err := os.Create(i.jarPath)
+	i.logger.Success("Downloaded %.2f MB to %s", float64(size)/(1024*1024), i.jarPath)
+
+	if i.cache != nil && !isSnapshot(i.version) {
+		if err := i.cache.store(i.version, i.jarPath); err != nil {
+			i.logger.Warning("Could not add JAR to shared cache: %v", err)
+		}
+	}
+	return nil
+}
+
+// installFromCache links a JAR from the shared cache into place. The install
+// is a hard link to the read-only cache object where possible, so it shares
+// that object's content. The signature is checked again so a stricter
+// signature setting also applies to cached JARs.
+func (i *Installer) installFromCache(client *http.Client, downloadURL, cached string) error {
+	i.logger.Info("Found %s in shared cache %s", i.version, i.cache.dir)
+
+	if err := i.verifySignature(client, downloadURL, cached); err != nil {
+		return &verificationError{err: err}
+	}
+
+	if err := linkOrCopy(cached, i.jarPath); err != nil {
+		return fmt.Errorf("failed to install from shared cache: %w", err)
+	}
+
+	i.logger.Success("Installed %s from shared cache", i.jarPath)
+	return nil
+}
+
+// interruptedError marks a download that failed mid-transfer and can be resumed.
+type interruptedError struct {
+	err error
+}
+
+func (e *interruptedError) Error() string {
+	return fmt.Sprintf("failed to write file: %v", e.err)
+}
+
+func (e *interruptedError) Unwrap() error {
+	return e.err
+}
+
+// transferJAR streams the JAR into the partial file, resuming it if possible.
+// It returns the hash of the complete file (nil without an expected checksum)
+// and the total number of bytes on disk.
+func (i *Installer) transferJAR(client *http.Client, downloadURL, partPath string, expected *expectedChecksum) (hash.Hash, int64, error) {
+	out, resp, offset, err := i.openDownload(client, downloadURL, partPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, 0, err
 	}
-	defer out.Close()
+	defer resp.Body.Close()
 
 	contentLength := resp.ContentLength
-	if contentLength > 0 {
-		i.logger.Info("File size: %.2f MB", float64(contentLength)/(1024*1024))
+	total := int64(-1)
+	if contentLength >= 0 {
+		total = offset + contentLength
+		i.logger.Info("File size: %.2f MB", float64(total)/(1024*1024))
+	}
+
+	// Hash the stream while it is written so the file is only read once
+	var body io.Reader = resp.Body
+	var hasher hash.Hash
+	if expected != nil {
+		hasher = expected.newHash()
+		if offset > 0 {
+			if err := hashFilePrefix(hasher, partPath, offset); err != nil {
+				out.Close()
+				return nil, 0, err
+			}
+		}
+		body = io.TeeReader(resp.Body, hasher)
 	}
 
 	written, err := io.Copy(out, &progressReader{
-		reader: resp.Body,
-		total:  contentLength,
+		reader:     body,
+		total:      total,
+		downloaded: offset,
 		onProgress: func(downloaded, total int64) {
 			if total > 0 {
 				percent := float64(downloaded) / float64(total) * 100
@@ -62,55 +240,134 @@ func (i *Installer) downloadJAR() error {
 	})
 	fmt.Println()
 
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		os.Remove(i.jarPath)
-		return fmt.Errorf("failed to write file: %w", err)
+		return nil, 0, &interruptedError{err: err}
 	}
 
-	i.logger.Success("Downloaded %.2f MB to %s", float64(written)/(1024*1024), i.jarPath)
-	return nil
+	return hasher, offset + written, nil
 }
 
-// createHTTPClient creates an HTTP client with optional proxy configuration.
-func (i *Installer) createHTTPClient() (*http.Client, error) {
-	if !i.config.Download.HasProxy() {
-		return http.DefaultClient, nil
+// openDownload requests the JAR and opens the partial file to write it to.
+// If a partial file with a known validator exists, the request asks for the
+// remaining bytes only; the returned offset is the number of bytes already present.
+func (i *Installer) openDownload(client *http.Client, downloadURL, partPath string) (*os.File, *http.Response, int64, error) {
+	offset, validator := partialDownload(partPath)
+
+	req, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		i.logger.Info("Resuming download from %.2f MB", float64(offset)/(1024*1024))
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to download: %w", err)
 	}
 
-	proxy := i.config.Download.Proxy
-	i.logger.Info("Using proxy: %s", proxy.URL)
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent && contentRangeStart(resp) == offset:
+		out, err := os.OpenFile(partPath, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			resp.Body.Close()
+			return nil, nil, 0, fmt.Errorf("failed to open partial file: %w", err)
+		}
+		return out, resp, offset, nil
+
+	case offset > 0 && (resp.StatusCode == http.StatusRequestedRangeNotSatisfiable || resp.StatusCode == http.StatusPartialContent):
+		// The partial file no longer matches the remote artifact, or the server
+		// sent a range other than the one requested; start over
+		resp.Body.Close()
+		i.logger.Warning("Server rejected resume request, restarting download")
+		removePartFile(partPath)
+		return i.openDownload(client, downloadURL, partPath)
+
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			i.logger.Warning("Server does not support resuming or the file changed, restarting download")
+		}
 
-	proxyURL, err := url.Parse(proxy.URL)
+	default:
+		resp.Body.Close()
+		return nil, nil, 0, fmt.Errorf("download failed with status: %s", resp.Status)
+	}
+
+	out, err := os.Create(partPath)
 	if err != nil {
-		return nil, fmt.Errorf("invalid proxy URL: %w", err)
-	}
-
-	// Add authentication if provided
-	if proxy.Username != "" {
-		proxyURL.User = url.UserPassword(proxy.Username, proxy.Password)
-	}
-
-	transport := &http.Transport{
-		Proxy: func(req *http.Request) (*url.URL, error) {
-			// Check noProxy list
-			if proxy.NoProxy != "" {
-				host := req.URL.Hostname()
-				for _, noProxyHost := range strings.Split(proxy.NoProxy, ",") {
-					noProxyHost = strings.TrimSpace(noProxyHost)
-					if noProxyHost == "" {
-						continue
-					}
-					// Match exact host or suffix (e.g., .internal.domain)
-					if host == noProxyHost || strings.HasSuffix(host, noProxyHost) {
-						return nil, nil // No proxy for this host
-					}
-				}
-			}
-			return proxyURL, nil
-		},
+		resp.Body.Close()
+		return nil, nil, 0, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	// Remember the validator so an interrupted download can be resumed safely
+	if validator := responseValidator(resp); validator != "" {
+		os.WriteFile(partPath+validatorFileSuffix, []byte(validator), 0644)
+	} else {
+		os.Remove(partPath + validatorFileSuffix)
+	}
+
+	return out, resp, 0, nil
+}
+
+// partialDownload returns the size and validator of a resumable partial file.
+// A zero offset means there is nothing to resume.
+func partialDownload(partPath string) (int64, string) {
+	info, err := os.Stat(partPath)
+	if err != nil || info.Size() == 0 {
+		return 0, ""
+	}
+
+	validator, err := os.ReadFile(partPath + validatorFileSuffix)
+	if err != nil || len(strings.TrimSpace(string(validator))) == 0 {
+		return 0, ""
+	}
+
+	return info.Size(), strings.TrimSpace(string(validator))
+}
+
+// responseValidator returns the value suitable for If-Range, preferring a strong ETag.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header, or -1.
+func contentRangeStart(resp *http.Response) int64 {
+	var start, end int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d", &start, &end); err != nil {
+		return -1
+	}
+	return start
+}
+
+// hashFilePrefix feeds the first n bytes of a file into the hash.
+func hashFilePrefix(h hash.Hash, path string, n int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read partial file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.CopyN(h, file, n); err != nil {
+		return fmt.Errorf("failed to read partial file: %w", err)
 	}
+	return nil
+}
 
-	return &http.Client{Transport: transport}, nil
+// removePartFile deletes a partial download along with its validator.
+func removePartFile(partPath string) {
+	os.Remove(partPath)
+	os.Remove(partPath + validatorFileSuffix)
 }
 
 // progressReader wraps an io.Reader to report download progress.
@@ -1,74 +1,31 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestCreateHTTPClient(t *testing.T) {
-	t.Run("returns default client when no proxy configured", func(t *testing.T) {
-		installer := &Installer{
-			config: &Config{
-				Download: DownloadConfig{
-					BaseURL: "http://example.com",
-				},
-			},
-			logger: NewLogger(),
-		}
-
-		client, err := installer.createHTTPClient()
-		require.NoError(t, err)
-		assert.Equal(t, http.DefaultClient, client)
-	})
-
-	t.Run("returns custom client with proxy", func(t *testing.T) {
-		installer := &Installer{
-			config: &Config{
-				Download: DownloadConfig{
-					BaseURL: "http://example.com",
-					Proxy: &ProxyConfig{
-						URL: "http://proxy:8080",
-					},
-				},
-			},
-			logger: NewLogger(),
-		}
-
-		client, err := installer.createHTTPClient()
-		require.NoError(t, err)
-		assert.NotEqual(t, http.DefaultClient, client)
-	})
-
-	t.Run("returns error for invalid proxy URL", func(t *testing.T) {
-		installer := &Installer{
-			config: &Config{
-				Download: DownloadConfig{
-					BaseURL: "http://example.com",
-					Proxy: &ProxyConfig{
-						URL: "://invalid-url",
-					},
-				},
-			},
-			logger: NewLogger(),
-		}
-
-		_, err := installer.createHTTPClient()
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "invalid proxy URL")
-	})
-}
-
 func TestDownloadJAR(t *testing.T) {
 	t.Run("downloads JAR successfully", func(t *testing.T) {
 		jarContent := []byte("fake jar content")
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, "/1.0.0/moderne-cli-1.0.0.jar", r.URL.Path)
+			if r.URL.Path != "/1.0.0/moderne-cli-1.0.0.jar" {
+				// No checksum sidecars published
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
 			w.Header().Set("Content-Length", "16")
 			w.WriteHeader(http.StatusOK)
 			w.Write(jarContent)
@@ -80,7 +37,7 @@ func TestDownloadJAR(t *testing.T) {
 
 		installer := &Installer{
 			version:     "1.0.0",
-			config:      &Config{Download: DownloadConfig{BaseURL: server.URL}},
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
 			binDir:      binDir,
 			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
 			jarFileName: "moderne-cli-1.0.0.jar",
@@ -120,7 +77,7 @@ func TestDownloadJAR(t *testing.T) {
 
 		installer := &Installer{
 			version:     "1.0.0",
-			config:      &Config{Download: DownloadConfig{BaseURL: server.URL}},
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
 			binDir:      binDir,
 			jarPath:     jarPath,
 			jarFileName: "moderne-cli-1.0.0.jar",
@@ -132,6 +89,178 @@ func TestDownloadJAR(t *testing.T) {
 		assert.False(t, serverCalled, "server should not have been called")
 	})
 
+	t.Run("verifies checksum sidecar", func(t *testing.T) {
+		jarContent := []byte("fake jar content")
+		sum := sha256.Sum256(jarContent)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				w.Write(jarContent)
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha256":
+				w.Write([]byte(hex.EncodeToString(sum[:])))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		binDir := filepath.Join(tmpDir, ".moderne", "bin")
+		err := os.MkdirAll(binDir, 0755)
+		require.NoError(t, err)
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL, Checksum: ChecksumRequired}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err = installer.downloadJAR()
+		require.NoError(t, err)
+		assert.FileExists(t, installer.jarPath)
+	})
+
+	t.Run("removes JAR on checksum mismatch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				w.Write([]byte("tampered jar content"))
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha1":
+				w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		binDir := filepath.Join(tmpDir, ".moderne", "bin")
+		err := os.MkdirAll(binDir, 0755)
+		require.NoError(t, err)
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err = installer.downloadJAR()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		assert.NoFileExists(t, installer.jarPath)
+		assert.NoFileExists(t, installer.jarPath+".part")
+	})
+
+	t.Run("removes JAR on invalid signature", func(t *testing.T) {
+		signer, publicKey := newTestSigningKey(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				w.Write([]byte("tampered jar content"))
+			case "/1.0.0/moderne-cli-1.0.0.jar.asc":
+				w.Write(signContent(t, signer, []byte("fake jar content")))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		binDir := filepath.Join(tmpDir, ".moderne", "bin")
+		err := os.MkdirAll(binDir, 0755)
+		require.NoError(t, err)
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{BaseURL: server.URL, SigningKey: publicKey}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err = installer.downloadJAR()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PGP signature verification failed")
+		assert.NoFileExists(t, installer.jarPath)
+		assert.NoFileExists(t, installer.jarPath+".part")
+	})
+
+	t.Run("does not install truncated download", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/1.0.0/moderne-cli-1.0.0.jar" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			// Promise more bytes than are sent to simulate a dropped connection
+			w.Header().Set("Content-Length", "1024")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("partial"))
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		binDir := filepath.Join(tmpDir, ".moderne", "bin")
+		err := os.MkdirAll(binDir, 0755)
+		require.NoError(t, err)
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL, Retry: fastRetry}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err = installer.downloadJAR()
+		assert.Error(t, err)
+		assert.NoFileExists(t, installer.jarPath)
+	})
+
+	t.Run("replaces stale partial file from previous run", func(t *testing.T) {
+		jarContent := []byte("fake jar content")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/1.0.0/moderne-cli-1.0.0.jar" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(jarContent)
+		}))
+		defer server.Close()
+
+		tmpDir := t.TempDir()
+		binDir := filepath.Join(tmpDir, ".moderne", "bin")
+		jarPath := filepath.Join(binDir, "moderne-cli-1.0.0.jar")
+		err := os.MkdirAll(binDir, 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(jarPath+".part", []byte("garbage from an interrupted run"), 0644)
+		require.NoError(t, err)
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
+			binDir:      binDir,
+			jarPath:     jarPath,
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err = installer.downloadJAR()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, jarContent, content)
+		assert.NoFileExists(t, jarPath+".part")
+	})
+
 	t.Run("returns error on HTTP failure", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNotFound)
@@ -145,7 +274,7 @@ func TestDownloadJAR(t *testing.T) {
 
 		installer := &Installer{
 			version:     "1.0.0",
-			config:      &Config{Download: DownloadConfig{BaseURL: server.URL}},
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
 			binDir:      binDir,
 			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
 			jarFileName: "moderne-cli-1.0.0.jar",
@@ -158,6 +287,206 @@ func TestDownloadJAR(t *testing.T) {
 	})
 }
 
+func TestDownloadJARResume(t *testing.T) {
+	jarContent := []byte("0123456789abcdefghij")
+	sum := sha256.Sum256(jarContent)
+
+	newServer := func(t *testing.T, etag string, ranges *[]string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				*ranges = append(*ranges, r.Header.Get("Range"))
+				w.Header().Set("ETag", etag)
+				http.ServeContent(w, r, "moderne-cli-1.0.0.jar", time.Time{}, bytes.NewReader(jarContent))
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha256":
+				w.Write([]byte(hex.EncodeToString(sum[:])))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	newInstaller := func(t *testing.T, baseURL string) *Installer {
+		binDir := filepath.Join(t.TempDir(), ".moderne", "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+
+		return &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: baseURL, Checksum: ChecksumRequired}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+	}
+
+	t.Run("resumes partial download when validator matches", func(t *testing.T) {
+		var ranges []string
+		server := newServer(t, `"v1"`, &ranges)
+		installer := newInstaller(t, server.URL)
+
+		partPath := installer.jarPath + ".part"
+		require.NoError(t, os.WriteFile(partPath, jarContent[:8], 0644))
+		require.NoError(t, os.WriteFile(partPath+".etag", []byte(`"v1"`), 0644))
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(installer.jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, jarContent, content)
+		assert.Equal(t, []string{"bytes=8-"}, ranges)
+		assert.NoFileExists(t, partPath)
+		assert.NoFileExists(t, partPath+".etag")
+	})
+
+	t.Run("restarts download when validator changed", func(t *testing.T) {
+		var ranges []string
+		server := newServer(t, `"v2"`, &ranges)
+		installer := newInstaller(t, server.URL)
+
+		partPath := installer.jarPath + ".part"
+		require.NoError(t, os.WriteFile(partPath, []byte("stale"), 0644))
+		require.NoError(t, os.WriteFile(partPath+".etag", []byte(`"v1"`), 0644))
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(installer.jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, jarContent, content)
+	})
+
+	t.Run("does not resume without validator", func(t *testing.T) {
+		var ranges []string
+		server := newServer(t, `"v1"`, &ranges)
+		installer := newInstaller(t, server.URL)
+
+		require.NoError(t, os.WriteFile(installer.jarPath+".part", []byte("01234"), 0644))
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+		assert.Equal(t, []string{""}, ranges)
+	})
+
+	t.Run("restarts download when server returns a different range", func(t *testing.T) {
+		var ranges []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				ranges = append(ranges, r.Header.Get("Range"))
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("Range") != "" {
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes 4-%d/%d", len(jarContent)-1, len(jarContent)))
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write(jarContent[4:])
+					return
+				}
+				w.Write(jarContent)
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha256":
+				w.Write([]byte(hex.EncodeToString(sum[:])))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		installer := newInstaller(t, server.URL)
+
+		partPath := installer.jarPath + ".part"
+		require.NoError(t, os.WriteFile(partPath, jarContent[:8], 0644))
+		require.NoError(t, os.WriteFile(partPath+".etag", []byte(`"v1"`), 0644))
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(installer.jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, jarContent, content)
+		assert.Equal(t, []string{"bytes=8-", ""}, ranges)
+		assert.NoFileExists(t, partPath)
+	})
+
+	t.Run("keeps partial file and validator when interrupted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/1.0.0/moderne-cli-1.0.0.jar" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "1024")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("partial"))
+		}))
+		defer server.Close()
+		installer := newInstaller(t, server.URL)
+		installer.config.Download.Checksum = ChecksumOff
+		installer.config.Download.Retry = fastRetry
+
+		err := installer.downloadJAR()
+		assert.Error(t, err)
+		assert.NoFileExists(t, installer.jarPath)
+
+		content, err := os.ReadFile(installer.jarPath + ".part")
+		require.NoError(t, err)
+		assert.Equal(t, "partial", string(content))
+
+		validator, err := os.ReadFile(installer.jarPath + ".part.etag")
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, string(validator))
+	})
+}
+
+func TestDownloadJARRetry(t *testing.T) {
+	t.Run("resumes after connection drops mid-transfer", func(t *testing.T) {
+		jarContent := []byte("0123456789abcdefghij")
+		sum := sha256.Sum256(jarContent)
+		var ranges []string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				ranges = append(ranges, r.Header.Get("Range"))
+				w.Header().Set("ETag", `"v1"`)
+				if len(ranges) == 1 {
+					// First attempt drops the connection after 8 bytes
+					w.Header().Set("Content-Length", strconv.Itoa(len(jarContent)))
+					w.WriteHeader(http.StatusOK)
+					w.Write(jarContent[:8])
+					return
+				}
+				http.ServeContent(w, r, "moderne-cli-1.0.0.jar", time.Time{}, bytes.NewReader(jarContent))
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha256":
+				w.Write([]byte(hex.EncodeToString(sum[:])))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		binDir := filepath.Join(t.TempDir(), ".moderne", "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL, Checksum: ChecksumRequired, Retry: fastRetry}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			logger:      NewLogger(),
+		}
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(installer.jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, jarContent, content)
+		assert.Equal(t, []string{"", "bytes=8-"}, ranges)
+	})
+}
+
 func TestProgressReader(t *testing.T) {
 	t.Run("reports progress correctly", func(t *testing.T) {
 		data := []byte("hello world")
@@ -180,6 +509,24 @@ func TestProgressReader(t *testing.T) {
 		assert.Equal(t, 5, n)
 		assert.Equal(t, []int64{5, 10}, progressCalls)
 	})
+
+	t.Run("reports progress from resumed offset", func(t *testing.T) {
+		var progressCalls []int64
+
+		pr := &progressReader{
+			reader:     &mockReader{data: []byte("world")},
+			total:      11,
+			downloaded: 6,
+			onProgress: func(downloaded, total int64) {
+				progressCalls = append(progressCalls, downloaded)
+			},
+		}
+
+		buf := make([]byte, 5)
+		n, _ := pr.Read(buf)
+		assert.Equal(t, 5, n)
+		assert.Equal(t, []int64{11}, progressCalls)
+	})
 }
 
 type mockReader struct {
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// fileTransport serves file:// URLs from the local file system so a mounted
+// Maven-layout directory can be used as baseUrl. Other schemes are passed to base.
+type fileTransport struct {
+	base http.RoundTripper
+}
+
+// newFileClient returns a client for file:// URLs. It needs no proxy, TLS or
+// credentials, so reading a local JAR and its sidecars never touches the network.
+func newFileClient() *http.Client {
+	return &http.Client{Transport: &fileTransport{base: http.DefaultTransport}}
+}
+
+func (t *fileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "file" {
+		return t.base.RoundTrip(req)
+	}
+
+	path, err := fileURLPath(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	response := func(status int, body io.ReadCloser, length int64) *http.Response {
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        make(http.Header),
+			Body:          body,
+			ContentLength: length,
+			Request:       req,
+		}
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return response(http.StatusNotFound, http.NoBody, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err == nil && info.IsDir() && strings.HasSuffix(req.URL.Path, "/") {
+		// List directories the way nginx autoindex_format json does, so
+		// version discovery works without maven-metadata.xml
+		listing, err := directoryListing(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp := response(http.StatusOK, io.NopCloser(bytes.NewReader(listing)), int64(len(listing)))
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	}
+	if err != nil || info.IsDir() {
+		file.Close()
+		return response(http.StatusNotFound, http.NoBody, 0), nil
+	}
+
+	resp := response(http.StatusOK, file, info.Size())
+	resp.Header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	if req.Method == http.MethodHead {
+		file.Close()
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
+
+// directoryListing renders the entries of an open directory as JSON.
+func directoryListing(dir *os.File) ([]byte, error) {
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := []listingEntry{}
+	for _, entry := range entries {
+		entryType := "file"
+		if entry.IsDir() {
+			entryType = "directory"
+		}
+		listing = append(listing, listingEntry{Name: entry.Name(), Type: entryType})
+	}
+	return json.Marshal(listing)
+}
+
+// fileURLPath converts a file:// URL to a local path.
+// Windows drive letters (file:///C:/dir) and UNC shares (file://server/share) are supported.
+func fileURLPath(u *url.URL) (string, error) {
+	path := u.Path
+
+	if u.Host != "" && u.Host != "localhost" {
+		if runtime.GOOS != "windows" {
+			return "", fmt.Errorf("unsupported file URL host %q", u.Host)
+		}
+		return `\\` + u.Host + filepath.FromSlash(path), nil
+	}
+
+	// "/C:/dir" -> "C:/dir"
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+
+	return filepath.FromSlash(path), nil
+}
+
+// fileURL converts a local path to a file:// URL.
+func fileURL(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	slashed := filepath.ToSlash(abs)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String(), nil
+}
+
+// versionFromJARName extracts the version from a moderne-cli-<version>.jar file name.
+func versionFromJARName(path string) (string, bool) {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, jarFilePrefix) || !strings.HasSuffix(name, jarFileSuffix) {
+		return "", false
+	}
+
+	version := strings.TrimSuffix(strings.TrimPrefix(name, jarFilePrefix), jarFileSuffix)
+	return version, version != ""
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFileRepository creates a Maven-layout directory with one version of the JAR.
+func writeFileRepository(t *testing.T, version string, content []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	versionDir := filepath.Join(dir, version)
+	require.NoError(t, os.MkdirAll(versionDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "maven-metadata.xml"), []byte(testMetadata), 0644))
+
+	jarPath := filepath.Join(versionDir, "moderne-cli-"+version+".jar")
+	require.NoError(t, os.WriteFile(jarPath, content, 0644))
+	sum := sha256.Sum256(content)
+	require.NoError(t, os.WriteFile(jarPath+".sha256", []byte(hex.EncodeToString(sum[:])), 0644))
+	return dir
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artifact.jar"), []byte("content"), 0644))
+	client := &http.Client{Transport: &fileTransport{base: http.DefaultTransport}}
+
+	t.Run("serves existing files", func(t *testing.T) {
+		u, err := fileURL(filepath.Join(dir, "artifact.jar"))
+		require.NoError(t, err)
+
+		resp, err := client.Get(u)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(7), resp.ContentLength)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(body))
+	})
+
+	t.Run("returns not found for missing files and directories", func(t *testing.T) {
+		for _, path := range []string{filepath.Join(dir, "missing.jar"), dir} {
+			u, err := fileURL(path)
+			require.NoError(t, err)
+
+			resp, err := client.Get(u)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
+
+func TestVersionFromJARName(t *testing.T) {
+	version, ok := versionFromJARName("/tmp/moderne-cli-3.57.9.jar")
+	assert.True(t, ok)
+	assert.Equal(t, "3.57.9", version)
+
+	_, ok = versionFromJARName("/tmp/cli.jar")
+	assert.False(t, ok)
+
+	_, ok = versionFromJARName("moderne-cli-.jar")
+	assert.False(t, ok)
+}
+
+func TestFileRepository(t *testing.T) {
+	content := []byte("fake jar content")
+	dir := writeFileRepository(t, "1.0.0", content)
+	baseURL, err := fileURL(dir)
+	require.NoError(t, err)
+
+	repository, err := NewRepository(&DownloadConfig{BaseURL: baseURL}, NewLogger())
+	require.NoError(t, err)
+
+	latest, err := repository.LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", latest)
+
+	binDir := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	installer := &Installer{
+		version:     "1.0.0",
+		config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: baseURL}},
+		binDir:      binDir,
+		jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+		jarFileName: "moderne-cli-1.0.0.jar",
+		repository:  repository,
+		logger:      NewLogger(),
+	}
+
+	require.NoError(t, installer.downloadJAR())
+	installed, err := os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, installed)
+}
+
+func TestInstallLocalJAR(t *testing.T) {
+	newInstaller := func(t *testing.T, localJAR string) *Installer {
+		binDir := filepath.Join(t.TempDir(), "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+		return &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, Checksum: ChecksumRequired}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			localJAR:    localJAR,
+			logger:      NewLogger(),
+		}
+	}
+
+	t.Run("installs and verifies a local JAR", func(t *testing.T) {
+		content := []byte("fake jar content")
+		dir := writeFileRepository(t, "1.0.0", content)
+		installer := newInstaller(t, filepath.Join(dir, "1.0.0", "moderne-cli-1.0.0.jar"))
+
+		require.NoError(t, installer.downloadJAR())
+		installed, err := os.ReadFile(installer.jarPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, installed)
+	})
+
+	t.Run("rejects a local JAR with a wrong checksum", func(t *testing.T) {
+		dir := writeFileRepository(t, "1.0.0", []byte("fake jar content"))
+		jarPath := filepath.Join(dir, "1.0.0", "moderne-cli-1.0.0.jar")
+		require.NoError(t, os.WriteFile(jarPath, []byte("tampered"), 0644))
+		installer := newInstaller(t, jarPath)
+
+		err := installer.downloadJAR()
+		assert.Error(t, err)
+		assert.NoFileExists(t, installer.jarPath)
+	})
+
+	t.Run("does not fetch the PAC script", func(t *testing.T) {
+		pacRequests := 0
+		pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pacRequests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer pacServer.Close()
+
+		dir := writeFileRepository(t, "1.0.0", []byte("fake jar content"))
+		installer := newInstaller(t, filepath.Join(dir, "1.0.0", "moderne-cli-1.0.0.jar"))
+		installer.config.Download.Proxy = &ProxyConfig{PacURL: pacServer.URL + "/proxy.pac"}
+
+		require.NoError(t, installer.downloadJAR())
+		assert.FileExists(t, installer.jarPath)
+		assert.Zero(t, pacRequests)
+	})
+
+	t.Run("fails for a missing file", func(t *testing.T) {
+		installer := newInstaller(t, filepath.Join(t.TempDir(), "moderne-cli-1.0.0.jar"))
+
+		err := installer.downloadJAR()
+		assert.ErrorContains(t, err, "local JAR not found")
+	})
+}
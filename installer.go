@@ -9,12 +9,16 @@ import (
 )
 
 const (
-	DefaultBaseURL = "https://repo1.maven.org/maven2/io/moderne/moderne-cli"
-	installDirName = ".moderne"
-	binDirName     = "bin"
-	jarFilePrefix  = "moderne-cli-"
-	jarFileSuffix  = ".jar"
-	aliasName      = "mod"
+	DefaultBaseURL      = "https://repo1.maven.org/maven2/io/moderne/moderne-cli"
+	installDirName      = ".moderne"
+	binDirName          = "bin"
+	jarFilePrefix       = "moderne-cli-"
+	jarFileSuffix       = ".jar"
+	partFileSuffix      = ".part"
+	validatorFileSuffix = ".etag"
+	aliasName           = "mod"
+	aliasMarker         = "# Moderne CLI alias (managed by installer)"
+	batchFileName       = "mod.bat"
 )
 
 // Installer manages the Moderne CLI installation process.
@@ -25,22 +29,24 @@ type Installer struct {
 	binDir      string
 	jarPath     string
 	jarFileName string
+	localJAR    string
+	repository  *Repository
+	cache       *artifactCache
 	logger      *Logger
+	// strictCommands makes a failing post-install command an error instead of a warning
+	strictCommands bool
 }
 
 // NewInstallerWithConfig creates a new Installer instance with the given config.
-func NewInstallerWithConfig(version string, config *Config) *Installer {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Printf("Warning: could not determine home directory: %v\n", err)
-		homeDir = "."
-	}
-
-	installDir := filepath.Join(homeDir, installDirName)
+// The repository may be nil, in which case one is created from the config on first use.
+func NewInstallerWithConfig(version string, config *Config, repository *Repository) *Installer {
+	installDir := defaultInstallDir()
 	binDir := filepath.Join(installDir, binDirName)
 	jarFileName := fmt.Sprintf("%s%s%s", jarFilePrefix, version, jarFileSuffix)
 	jarPath := filepath.Join(binDir, jarFileName)
 
+	logger := NewLogger()
+
 	return &Installer{
 		version:     version,
 		config:      config,
@@ -48,15 +54,41 @@ func NewInstallerWithConfig(version string, config *Config) *Installer {
 		binDir:      binDir,
 		jarPath:     jarPath,
 		jarFileName: jarFileName,
-		logger:      NewLogger(),
+		repository:  repository,
+		cache:       newArtifactCache(config, logger),
+		logger:      logger,
+	}
+}
+
+// withVersion returns a copy of the installer for another version in the same directory.
+func (i *Installer) withVersion(version string) *Installer {
+	other := *i
+	other.version = version
+	other.jarFileName = fmt.Sprintf("%s%s%s", jarFilePrefix, version, jarFileSuffix)
+	other.jarPath = filepath.Join(i.binDir, other.jarFileName)
+	other.localJAR = ""
+	return &other
+}
+
+// defaultInstallDir returns ~/.moderne, or .moderne when the home directory is unknown.
+func defaultInstallDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Printf("Warning: could not determine home directory: %v\n", err)
+		homeDir = "."
 	}
+	return filepath.Join(homeDir, installDirName)
 }
 
 // Run executes the full installation process.
 func (i *Installer) Run() error {
 	i.logger.Step("Starting Moderne CLI installation")
 	i.logger.Info("Version: %s", i.version)
-	i.logger.Info("Download URL: %s", i.config.Download.BaseURL)
+	if i.repository != nil {
+		i.logger.Info("Download URL: %s", i.repository.Describe())
+	} else {
+		i.logger.Info("Download URL: %s", redactURL(i.config.Download.BaseURL))
+	}
 	i.logger.Info("Install directory: %s", i.installDir)
 
 	if err := i.createDirectories(); err != nil {
@@ -67,6 +99,10 @@ func (i *Installer) Run() error {
 		return fmt.Errorf("failed to download JAR: %w", err)
 	}
 
+	if err := i.setCurrent(); err != nil {
+		return fmt.Errorf("failed to activate version: %w", err)
+	}
+
 	if err := i.configureShellAlias(); err != nil {
 		return fmt.Errorf("failed to configure shell alias: %w", err)
 	}
@@ -97,6 +133,10 @@ func (i *Installer) printCompletionMessage() {
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println()
 	fmt.Println("JAR location:", i.jarPath)
+	if i.repository != nil && i.repository.Active() != nil {
+		active := i.repository.Active()
+		fmt.Printf("Downloaded from: %s (%s)\n", active.name, redactURL(active.baseURL))
+	}
 	fmt.Println()
 
 	switch runtime.GOOS {
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// versionsManifestName is an optional JSON manifest listing the published versions.
+const versionsManifestName = "versions.json"
+
+// maxListingSize bounds how much of a directory listing is read.
+const maxListingSize = 10 << 20
+
+// hrefPattern extracts link targets from an HTML directory listing.
+var hrefPattern = regexp.MustCompile(`(?i)<a\s[^>]*href\s*=\s*["']([^"']+)["']`)
+
+// errNoListing marks a source that does not publish the requested listing.
+var errNoListing = errors.New("not found")
+
+// versionsManifest is the object form of versions.json. A plain JSON array of
+// version strings is accepted as well.
+type versionsManifest struct {
+	Versions []string `json:"versions"`
+	Latest   string   `json:"latest,omitempty"`
+	Release  string   `json:"release,omitempty"`
+}
+
+// listingEntry is one entry of an nginx autoindex_format json listing.
+type listingEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// discoverVersions finds the published versions of a repository without
+// maven-metadata.xml. It reads versions.json if present, otherwise the
+// directory listing at baseURL (Apache/nginx autoindex in HTML or JSON).
+// The result has the shape of Maven metadata so the usual selection applies.
+func discoverVersions(baseURL string, client *http.Client) (*MavenMetadata, error) {
+	metadata, err := fetchVersionsManifest(baseURL, client)
+	if err == nil {
+		return metadata, nil
+	}
+	if !errors.Is(err, errNoListing) {
+		return nil, err
+	}
+
+	versions, err := fetchDirectoryListing(baseURL, client)
+	if errors.Is(err, errNoListing) {
+		return nil, fmt.Errorf("no maven-metadata.xml, %s or directory listing found at %s", versionsManifestName, redactURL(baseURL))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no version directories found at %s", redactURL(baseURL))
+	}
+
+	return &MavenMetadata{Versioning: Versioning{Versions: versions}}, nil
+}
+
+// fetchVersionsManifest reads versions.json from the repository.
+func fetchVersionsManifest(baseURL string, client *http.Client) (*MavenMetadata, error) {
+	manifestURL := baseURL + "/" + versionsManifestName
+	body, _, err := fetchListing(client, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest versionsManifest
+	if err := json.Unmarshal(body, &manifest.Versions); err != nil {
+		if err := json.Unmarshal(body, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", versionsManifestName, err)
+		}
+	}
+	if len(manifest.Versions) == 0 {
+		return nil, fmt.Errorf("no versions listed in %s", redactURL(manifestURL))
+	}
+
+	return &MavenMetadata{Versioning: Versioning{
+		Versions: manifest.Versions,
+		Latest:   manifest.Latest,
+		Release:  manifest.Release,
+	}}, nil
+}
+
+// fetchDirectoryListing returns the version directories listed at baseURL.
+func fetchDirectoryListing(baseURL string, client *http.Client) ([]string, error) {
+	body, contentType, err := fetchListing(client, baseURL+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/json" {
+		var entries []listingEntry
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse directory listing: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Type == "directory" {
+				names = append(names, entry.Name)
+			}
+		}
+	} else {
+		for _, match := range hrefPattern.FindAllStringSubmatch(string(body), -1) {
+			// Only relative links to subdirectories: "3.57.9/" or "./3.57.9/"
+			href := strings.TrimPrefix(match[1], "./")
+			if !strings.HasSuffix(href, "/") || strings.ContainsAny(href, "?#:") {
+				continue
+			}
+			name, err := url.PathUnescape(strings.TrimSuffix(href, "/"))
+			if err == nil && !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	// Keep names that look like versions, dropping "..", "archive" and the like
+	var versions []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if _, ok := parseSemver(name); ok && !seen[name] {
+			seen[name] = true
+			versions = append(versions, name)
+		}
+	}
+	return versions, nil
+}
+
+// fetchListing downloads a listing or manifest, returning errNoListing for 404.
+func fetchListing(client *http.Client, listingURL string) ([]byte, string, error) {
+	resp, err := client.Get(listingURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", redactURL(listingURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", errNoListing
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: %s", redactURL(listingURL), resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxListingSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", redactURL(listingURL), err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
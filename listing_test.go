@@ -0,0 +1,154 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apacheListing = `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">
+<html><head><title>Index of /moderne-cli</title></head><body>
+<h1>Index of /moderne-cli</h1>
+<table>
+<tr><th><a href="?C=N;O=D">Name</a></th><th><a href="?C=M;O=A">Last modified</a></th></tr>
+<tr><td><a href="/">Parent Directory</a></td></tr>
+<tr><td><a href="3.57.9/">3.57.9/</a></td><td>2024-01-01 12:00</td></tr>
+<tr><td><a href="3.57.10/">3.57.10/</a></td><td>2024-01-08 12:00</td></tr>
+<tr><td><a href="3.58.0-rc.1/">3.58.0-rc.1/</a></td><td>2024-01-09 12:00</td></tr>
+<tr><td><a href="archive/">archive/</a></td><td>2024-01-01 12:00</td></tr>
+<tr><td><a href="README.txt">README.txt</a></td><td>2024-01-01 12:00</td></tr>
+</table></body></html>`
+
+const nginxListing = `<html>
+<head><title>Index of /moderne-cli/</title></head>
+<body>
+<h1>Index of /moderne-cli/</h1><hr><pre><a href="../">../</a>
+<a href="3.56.0/">3.56.0/</a>                                            01-Jan-2024 12:00       -
+<a href="./3.57.0/">3.57.0/</a>                                            01-Jan-2024 12:00       -
+<a href="https://example.com/4.0.0/">4.0.0/</a>
+</pre><hr></body>
+</html>`
+
+// newListingServer serves the given paths and returns 404 for everything else.
+func newListingServer(t *testing.T, contentType string, files map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscoverVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		files       map[string]string
+		expected    []string
+	}{
+		{
+			name:        "apache autoindex",
+			contentType: "text/html;charset=UTF-8",
+			files:       map[string]string{"/": apacheListing},
+			expected:    []string{"3.57.9", "3.57.10", "3.58.0-rc.1"},
+		},
+		{
+			name:        "nginx autoindex",
+			contentType: "text/html",
+			files:       map[string]string{"/": nginxListing},
+			expected:    []string{"3.56.0", "3.57.0"},
+		},
+		{
+			name:        "nginx json autoindex",
+			contentType: "application/json",
+			files: map[string]string{"/": `[
+				{"name":"3.57.9","type":"directory","mtime":"Mon, 01 Jan 2024 12:00:00 GMT"},
+				{"name":"3.57.9.jar","type":"file","mtime":"Mon, 01 Jan 2024 12:00:00 GMT","size":1},
+				{"name":"3.58.0","type":"directory","mtime":"Mon, 08 Jan 2024 12:00:00 GMT"}
+			]`},
+			expected: []string{"3.57.9", "3.58.0"},
+		},
+		{
+			name:        "versions.json array",
+			contentType: "application/json",
+			files:       map[string]string{"/versions.json": `["3.57.9", "3.57.10"]`, "/": apacheListing},
+			expected:    []string{"3.57.9", "3.57.10"},
+		},
+		{
+			name:        "versions.json object",
+			contentType: "application/json",
+			files:       map[string]string{"/versions.json": `{"versions": ["3.57.9", "3.58.0"], "latest": "3.58.0"}`},
+			expected:    []string{"3.57.9", "3.58.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newListingServer(t, tt.contentType, tt.files)
+
+			metadata, err := discoverVersions(server.URL, http.DefaultClient)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, metadata.Versioning.Versions)
+		})
+	}
+
+	t.Run("returns error when nothing is published", func(t *testing.T) {
+		server := newListingServer(t, "text/html", nil)
+
+		_, err := discoverVersions(server.URL, http.DefaultClient)
+		assert.ErrorContains(t, err, "no maven-metadata.xml, versions.json or directory listing found")
+	})
+
+	t.Run("returns error for listing without versions", func(t *testing.T) {
+		server := newListingServer(t, "text/html", map[string]string{"/": `<a href="docs/">docs/</a>`})
+
+		_, err := discoverVersions(server.URL, http.DefaultClient)
+		assert.ErrorContains(t, err, "no version directories found")
+	})
+
+	t.Run("returns error for invalid versions.json", func(t *testing.T) {
+		server := newListingServer(t, "application/json", map[string]string{"/versions.json": `{"versions": 1}`})
+
+		_, err := discoverVersions(server.URL, http.DefaultClient)
+		assert.ErrorContains(t, err, "failed to parse versions.json")
+	})
+}
+
+func TestRepositoryDiscoversVersionsWithoutMetadata(t *testing.T) {
+	t.Run("http directory listing", func(t *testing.T) {
+		server := newListingServer(t, "text/html", map[string]string{"/": apacheListing})
+		repository, err := NewRepository(&DownloadConfig{BaseURL: server.URL}, NewLogger())
+		require.NoError(t, err)
+
+		latest, err := repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", latest)
+
+		resolved, err := repository.ResolveVersion("~3.57.0")
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", resolved)
+	})
+
+	t.Run("file repository", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, version := range []string{"3.57.9", "3.57.10"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+		}
+		repository, err := NewRepository(&DownloadConfig{BaseURL: mustFileURL(t, dir)}, NewLogger())
+		require.NoError(t, err)
+
+		latest, err := repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", latest)
+	})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteVersion is one entry of the list-remote output.
+type remoteVersion struct {
+	Version    string `json:"version"`
+	Prerelease bool   `json:"prerelease"`
+	Installed  bool   `json:"installed"`
+}
+
+// remoteListing is the list-remote output, newest version first.
+type remoteListing struct {
+	LastUpdated string          `json:"lastUpdated,omitempty"`
+	Versions    []remoteVersion `json:"versions"`
+}
+
+// runListRemote implements the list-remote subcommand.
+func runListRemote(config *Config, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("list-remote", flag.ExitOnError)
+	limit := flags.Int("limit", 0, "Show only the newest N versions (default: all)")
+	asJSON := flags.Bool("json", false, "Print JSON instead of a table")
+	offline := flags.Bool("offline", false, "Use cached repository metadata only")
+	refresh := flags.Bool("refresh", false, "Ignore cached repository metadata")
+	flags.Parse(args)
+
+	if *offline && *refresh {
+		return fmt.Errorf("--offline and --refresh cannot be used together")
+	}
+
+	// Keep stdout parseable: diagnostics such as proxy selection go to stderr
+	logger := NewStderrLogger()
+	repository, err := NewRepository(&config.Download, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	repository.cache = newMetadataCache(config, *offline, *refresh, logger)
+
+	metadata, err := repository.Metadata()
+	if err != nil {
+		return err
+	}
+
+	installed, err := installedVersions(filepath.Join(defaultInstallDir(), binDirName))
+	if err != nil {
+		return fmt.Errorf("failed to read installed versions: %w", err)
+	}
+
+	listing := newRemoteListing(metadata, installed, *limit)
+	if *asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listing)
+	}
+	listing.print(out)
+	return nil
+}
+
+// newRemoteListing sorts the metadata versions newest first and marks
+// prereleases and installed versions. A positive limit keeps the newest N.
+func newRemoteListing(metadata *MavenMetadata, installed []string, limit int) *remoteListing {
+	isInstalled := map[string]bool{}
+	for _, version := range installed {
+		isInstalled[version] = true
+	}
+
+	versions := availableVersions(metadata)
+	listing := &remoteListing{LastUpdated: formatLastUpdated(metadata.Versioning.LastUpdated), Versions: []remoteVersion{}}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if limit > 0 && len(listing.Versions) == limit {
+			break
+		}
+		listing.Versions = append(listing.Versions, remoteVersion{
+			Version:    versions[i],
+			Prerelease: isPrerelease(versions[i]),
+			Installed:  isInstalled[versions[i]],
+		})
+	}
+	return listing
+}
+
+// print writes the listing as an aligned table.
+func (l *remoteListing) print(out io.Writer) {
+	if l.LastUpdated != "" {
+		fmt.Fprintf(out, "Last updated: %s\n\n", l.LastUpdated)
+	}
+	if len(l.Versions) == 0 {
+		fmt.Fprintln(out, "No versions found")
+		return
+	}
+
+	width := 0
+	for _, v := range l.Versions {
+		width = max(width, len(v.Version))
+	}
+	for _, v := range l.Versions {
+		var marks []string
+		if v.Prerelease {
+			marks = append(marks, "prerelease")
+		}
+		if v.Installed {
+			marks = append(marks, "installed")
+		}
+		line := v.Version
+		if len(marks) > 0 {
+			line = fmt.Sprintf("%-*s  (%s)", width, v.Version, strings.Join(marks, ", "))
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// formatLastUpdated renders a Maven yyyyMMddHHmmss timestamp, or returns it
+// unchanged when it does not parse.
+func formatLastUpdated(value string) string {
+	parsed, err := time.Parse(metadataTimestampFmt, value)
+	if err != nil {
+		return value
+	}
+	return parsed.Format("2006-01-02 15:04:05 UTC")
+}
+
+// installedVersions lists the CLI versions whose JARs are in binDir, oldest first.
+// A missing directory means nothing is installed.
+func installedVersions(binDir string) ([]string, error) {
+	entries, err := os.ReadDir(binDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if version, ok := versionFromJARName(entry.Name()); ok {
+			versions = append(versions, version)
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
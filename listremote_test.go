@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRemoteListing(t *testing.T) {
+	metadata := &MavenMetadata{Versioning: Versioning{
+		Versions:    []string{"3.57.9", "3.57.10", "3.9.0", "3.58.0-rc.1"},
+		LastUpdated: "20240102030405",
+	}}
+
+	listing := newRemoteListing(metadata, []string{"3.57.9"}, 0)
+	assert.Equal(t, "2024-01-02 03:04:05 UTC", listing.LastUpdated)
+	assert.Equal(t, []remoteVersion{
+		{Version: "3.58.0-rc.1", Prerelease: true},
+		{Version: "3.57.10"},
+		{Version: "3.57.9", Installed: true},
+		{Version: "3.9.0"},
+	}, listing.Versions)
+
+	limited := newRemoteListing(metadata, nil, 2)
+	require.Len(t, limited.Versions, 2)
+	assert.Equal(t, "3.57.10", limited.Versions[1].Version)
+}
+
+func TestRemoteListingPrint(t *testing.T) {
+	listing := &remoteListing{
+		LastUpdated: "2024-01-02 03:04:05 UTC",
+		Versions: []remoteVersion{
+			{Version: "3.58.0-rc.1", Prerelease: true},
+			{Version: "3.57.10", Installed: true},
+			{Version: "3.9.0"},
+		},
+	}
+
+	var out bytes.Buffer
+	listing.print(&out)
+	assert.Equal(t, `Last updated: 2024-01-02 03:04:05 UTC
+
+3.58.0-rc.1  (prerelease)
+3.57.10      (installed)
+3.9.0
+`, out.String())
+}
+
+func TestInstalledVersions(t *testing.T) {
+	binDir := t.TempDir()
+	for _, name := range []string{"moderne-cli-3.57.10.jar", "moderne-cli-3.57.9.jar", "moderne-cli-3.58.0.jar.part", "mod.bat"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), nil, 0644))
+	}
+
+	versions, err := installedVersions(binDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.57.9", "3.57.10"}, versions)
+
+	versions, err = installedVersions(filepath.Join(binDir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestRunListRemote(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	binDir := filepath.Join(home, installDirName, binDirName)
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-3.57.9.jar"), nil, 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(syncMetadata))
+	}))
+	defer server.Close()
+	config := &Config{Download: DownloadConfig{BaseURL: server.URL}}
+
+	var out bytes.Buffer
+	require.NoError(t, runListRemote(config, []string{"--json", "--limit", "3"}, &out))
+
+	var listing remoteListing
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listing))
+	assert.Equal(t, []remoteVersion{
+		{Version: "3.58.0-rc.1", Prerelease: true},
+		{Version: "3.57.10"},
+		{Version: "3.57.9", Installed: true},
+	}, listing.Versions)
+}
@@ -1,31 +1,50 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // Logger provides formatted logging for the installer.
-type Logger struct{}
+type Logger struct {
+	out io.Writer
+}
 
-// NewLogger creates a new Logger instance.
+// NewLogger creates a new Logger instance writing to stdout.
 func NewLogger() *Logger {
 	return &Logger{}
 }
 
+// NewStderrLogger creates a Logger writing to stderr, for commands whose
+// stdout is meant to be parsed.
+func NewStderrLogger() *Logger {
+	return &Logger{out: os.Stderr}
+}
+
+func (l *Logger) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
+	}
+	return l.out
+}
+
 // Step logs a major installation step.
 func (l *Logger) Step(format string, args ...interface{}) {
-	fmt.Printf("\n[*] "+format+"\n", args...)
+	fmt.Fprintf(l.writer(), "\n[*] "+format+"\n", args...)
 }
 
 // Info logs an informational message.
 func (l *Logger) Info(format string, args ...interface{}) {
-	fmt.Printf("    "+format+"\n", args...)
+	fmt.Fprintf(l.writer(), "    "+format+"\n", args...)
 }
 
 // Success logs a success message.
 func (l *Logger) Success(format string, args ...interface{}) {
-	fmt.Printf("    [OK] "+format+"\n", args...)
+	fmt.Fprintf(l.writer(), "    [OK] "+format+"\n", args...)
 }
 
 // Warning logs a warning message.
 func (l *Logger) Warning(format string, args ...interface{}) {
-	fmt.Printf("    [WARN] "+format+"\n", args...)
+	fmt.Fprintf(l.writer(), "    [WARN] "+format+"\n", args...)
 }
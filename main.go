@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 func main() {
@@ -15,17 +16,120 @@ func main() {
 		configSource = "defaults"
 	}
 
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sync":
+			fmt.Printf("Using configuration from: %s\n", configSource)
+			if err := runSync(config, os.Args[2:]); err != nil {
+				fmt.Printf("Sync failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "list-remote":
+			if err := runListRemote(config, os.Args[2:], os.Stdout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "cache":
+			if err := runCache(config, os.Args[2:], os.Stdout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "use":
+			if err := runUse(config, os.Args[2:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "list":
+			if err := runList(filepath.Join(defaultInstallDir(), binDirName), os.Stdout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "upgrade":
+			fmt.Printf("Using configuration from: %s\n", configSource)
+			if err := runUpgrade(config, os.Args[2:]); err != nil {
+				fmt.Printf("Upgrade failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "uninstall":
+			if err := runUninstall(config, os.Args[2:], os.Stdout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "serve":
+			if err := runServe(os.Args[2:]); err != nil {
+				fmt.Printf("Serve failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Parse CLI flags
-	version := flag.String("version", "", "Version of the Moderne CLI to install (default: latest)")
+	version := flag.String("version", "", "Version or constraint (e.g. 3.57, ~3.57.0, \">=3.50 <4\") of the Moderne CLI to install (default: latest)")
+	jar := flag.String("jar", "", "Install from a pre-downloaded moderne-cli-<version>.jar instead of downloading")
+	offline := flag.Bool("offline", false, "Use cached repository metadata only")
+	refresh := flag.Bool("refresh", false, "Ignore cached repository metadata")
 	flag.Parse()
 
+	if *offline && *refresh {
+		fmt.Println("Error: -offline and -refresh cannot be used together")
+		os.Exit(1)
+	}
+
 	fmt.Printf("Using configuration from: %s\n", configSource)
 
+	// The repository is only created once metadata or a download is needed,
+	// since setting it up may fetch a PAC script; a -jar install never needs it
+	var repository *Repository
+	loadRepository := func() *Repository {
+		if repository == nil {
+			created, err := NewRepository(&config.Download, NewLogger())
+			if err != nil {
+				fmt.Printf("Error: failed to create HTTP client: %v\n", err)
+				os.Exit(1)
+			}
+			created.cache = newMetadataCache(config, *offline, *refresh, NewLogger())
+			repository = created
+		}
+		return repository
+	}
+
 	// Determine version
 	targetVersion := *version
+	if targetVersion == "" && *jar != "" {
+		derived, ok := versionFromJARName(*jar)
+		if !ok {
+			fmt.Printf("Error: cannot determine version from %s\n", *jar)
+			fmt.Println("Please specify a version using -version flag")
+			os.Exit(1)
+		}
+		targetVersion = derived
+	}
+	if isVersionConstraint(targetVersion) {
+		if *jar != "" {
+			fmt.Println("Error: -jar requires an exact -version")
+			os.Exit(1)
+		}
+		fmt.Printf("Resolving version constraint %s...\n", targetVersion)
+		resolved, err := loadRepository().ResolveVersion(targetVersion)
+		if err != nil {
+			fmt.Printf("Error: failed to resolve version: %v\n", err)
+			os.Exit(1)
+		}
+		targetVersion = resolved
+		fmt.Printf("Resolved version: %s\n", targetVersion)
+	}
 	if targetVersion == "" {
 		fmt.Println("No version specified, fetching latest version...")
-		latest, err := FetchLatestVersion(config.Download.BaseURL, nil)
+		latest, err := loadRepository().LatestVersion()
 		if err != nil {
 			fmt.Printf("Error: failed to determine latest version: %v\n", err)
 			fmt.Println("Please specify a version using -version flag")
@@ -35,7 +139,11 @@ func main() {
 		fmt.Printf("Latest version: %s\n", targetVersion)
 	}
 
-	installer := NewInstallerWithConfig(targetVersion, config)
+	if *jar == "" {
+		loadRepository()
+	}
+	installer := NewInstallerWithConfig(targetVersion, config, repository)
+	installer.localJAR = *jar
 	if err := installer.Run(); err != nil {
 		fmt.Printf("Installation failed: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cacheDirName         = "cache"
+	metadataCacheDirName = "metadata"
+	xdgCacheAppName      = "moderne-cli-installer"
+)
+
+// metadataCache stores maven-metadata.xml per repository URL and revalidates
+// it with conditional requests once its TTL has passed.
+type metadataCache struct {
+	dir     string
+	ttl     time.Duration
+	offline bool
+	refresh bool
+	now     func() time.Time
+	logger  *Logger
+}
+
+// metadataCacheEntry describes a cached metadata file.
+type metadataCacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// newMetadataCache creates the metadata cache for the configuration.
+// With offline set only cached copies are used; with refresh set the cache is
+// bypassed and overwritten.
+func newMetadataCache(config *Config, offline, refresh bool, logger *Logger) *metadataCache {
+	return &metadataCache{
+		dir:     metadataCacheDir(),
+		ttl:     config.MetadataTTL(),
+		offline: offline,
+		refresh: refresh,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// metadataCacheDir returns $XDG_CACHE_HOME/moderne-cli-installer/metadata if
+// XDG_CACHE_HOME is set, and ~/.moderne/cache/metadata otherwise.
+func metadataCacheDir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, xdgCacheAppName, metadataCacheDirName)
+	}
+	return filepath.Join(defaultInstallDir(), cacheDirName, metadataCacheDirName)
+}
+
+// fetch returns the metadata at baseURL, from the cache when it is fresh.
+// If the repository cannot be reached or answers with a server error, a stale
+// cached copy is used instead.
+func (c *metadataCache) fetch(baseURL string, client *http.Client) (*MavenMetadata, error) {
+	metadataURL := baseURL + "/" + metadataFileName
+	bodyPath, entryPath := c.paths(metadataURL)
+	entry, cached := c.load(bodyPath, entryPath)
+
+	if c.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("no cached metadata for %s (offline)", redactURL(metadataURL))
+		}
+		c.logger.Info("Using cached metadata from %s (offline)", entry.FetchedAt.Local().Format(time.DateTime))
+		return parseMetadata(cached)
+	}
+
+	if cached != nil && !c.refresh && c.now().Sub(entry.FetchedAt) < c.ttl {
+		c.logger.Info("Using cached metadata from %s", entry.FetchedAt.Local().Format(time.DateTime))
+		return parseMetadata(cached)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if cached != nil && !c.refresh {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if cached != nil && !c.refresh {
+			c.logger.Warning("Failed to fetch metadata (%v), using cached copy from %s", err, entry.FetchedAt.Local().Format(time.DateTime))
+			return parseMetadata(cached)
+		}
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		entry.FetchedAt = c.now()
+		c.save(entryPath, bodyPath, entry, nil)
+		c.logger.Info("Cached metadata is up to date")
+		return parseMetadata(cached)
+
+	case (resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests) && cached != nil && !c.refresh:
+		// The repository is down or throttling, not missing the file
+		c.logger.Warning("Failed to fetch metadata (%s), using cached copy from %s", resp.Status, entry.FetchedAt.Local().Format(time.DateTime))
+		return parseMetadata(cached)
+
+	case resp.StatusCode != http.StatusOK:
+		return nil, &metadataStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	metadata, err := parseMetadata(body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.save(entryPath, bodyPath, &metadataCacheEntry{
+		URL:          redactURL(metadataURL),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    c.now(),
+	}, body)
+	return metadata, nil
+}
+
+// paths returns the cache files for a metadata URL. Credentials in the URL
+// are not part of the key, so rotating them keeps the cache.
+func (c *metadataCache) paths(metadataURL string) (string, string) {
+	key := metadataURL
+	if parsed, err := url.Parse(metadataURL); err == nil {
+		parsed.User = nil
+		key = parsed.String()
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:16])
+	return filepath.Join(c.dir, name+".xml"), filepath.Join(c.dir, name+".json")
+}
+
+// load reads a cached entry, returning nil content if there is none.
+func (c *metadataCache) load(bodyPath, entryPath string) (*metadataCacheEntry, []byte) {
+	content, err := os.ReadFile(entryPath)
+	if err != nil {
+		return nil, nil
+	}
+	var entry metadataCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, nil
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	return &entry, body
+}
+
+// save writes the cache entry, and the body unless it is nil. Failures only
+// cost a cache miss next time, so they are logged rather than returned.
+func (c *metadataCache) save(entryPath, bodyPath string, entry *metadataCacheEntry, body []byte) {
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(c.dir, 0755)
+	}
+	if err == nil && body != nil {
+		err = writeFileAtomic(bodyPath, body)
+	}
+	if err == nil {
+		err = writeFileAtomic(entryPath, content)
+	}
+	if err != nil {
+		c.logger.Warning("Could not cache metadata: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// noProxyMatcher decides which hosts bypass the proxy. It follows the
+// semantics of Go's net/http and curl for NO_PROXY entries:
+//
+//   - "*" matches every host
+//   - "example.com" matches example.com and any subdomain, but not notexample.com
+//   - ".example.com" and "*.example.com" match subdomains only
+//   - IP literals (including IPv6, optionally in brackets) match that address
+//   - CIDR blocks such as 10.0.0.0/8 or fd00::/8 match any IP target inside them
+//   - a ":port" suffix restricts any of the above to that port
+//
+// Hostnames are never resolved, so CIDR and IP entries only match IP targets.
+type noProxyMatcher struct {
+	all      bool
+	addrs    []noProxyAddr
+	prefixes []netip.Prefix
+	domains  []noProxyDomain
+}
+
+// noProxyAddr is an IP literal entry with an optional port.
+type noProxyAddr struct {
+	addr netip.Addr
+	port string
+}
+
+// noProxyDomain is a host name entry with an optional port.
+type noProxyDomain struct {
+	suffix         string
+	subdomainsOnly bool
+	port           string
+}
+
+// parseNoProxy builds a matcher from no-proxy entries. Each entry may itself
+// be a comma-separated list; blank and malformed entries are ignored.
+func parseNoProxy(entries ...string) *noProxyMatcher {
+	matcher := &noProxyMatcher{}
+
+	for _, list := range entries {
+		for _, entry := range strings.Split(list, ",") {
+			matcher.add(strings.ToLower(strings.TrimSpace(entry)))
+		}
+	}
+
+	return matcher
+}
+
+func (m *noProxyMatcher) add(entry string) {
+	if entry == "" {
+		return
+	}
+	if entry == "*" {
+		m.all = true
+		return
+	}
+
+	if addr, bits, ok := strings.Cut(entry, "/"); ok {
+		// Brackets may wrap the address of an IPv6 CIDR, as in [fd00::]/8
+		addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+		prefix, err := netip.ParsePrefix(addr + "/" + bits)
+		if err == nil {
+			m.prefixes = append(m.prefixes, prefix.Masked())
+		}
+		return
+	}
+
+	host, port := splitNoProxyHostPort(entry)
+	if host == "" {
+		return
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		m.addrs = append(m.addrs, noProxyAddr{addr: addr.Unmap(), port: port})
+		return
+	}
+
+	domain := noProxyDomain{port: port}
+	host = strings.TrimSuffix(host, ".")
+	switch {
+	case strings.HasPrefix(host, "*."):
+		domain.suffix = host[1:]
+		domain.subdomainsOnly = true
+	case strings.HasPrefix(host, "."):
+		domain.suffix = host
+		domain.subdomainsOnly = true
+	default:
+		domain.suffix = host
+	}
+	m.domains = append(m.domains, domain)
+}
+
+// splitNoProxyHostPort separates an optional port from an entry, handling
+// bracketed and bare IPv6 literals.
+func splitNoProxyHostPort(entry string) (string, string) {
+	if strings.HasPrefix(entry, "[") {
+		if host, port, err := net.SplitHostPort(entry); err == nil {
+			return host, port
+		}
+		return strings.Trim(entry, "[]"), ""
+	}
+
+	// More than one colon without brackets is a bare IPv6 address
+	if strings.Count(entry, ":") == 1 {
+		if host, port, err := net.SplitHostPort(entry); err == nil {
+			return host, port
+		}
+	}
+
+	return entry, ""
+}
+
+// matches reports whether requests to target should bypass the proxy.
+func (m *noProxyMatcher) matches(target *url.URL) bool {
+	if m == nil {
+		return false
+	}
+	if m.all {
+		return true
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	port := target.Port()
+	if port == "" {
+		switch target.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		for _, entry := range m.addrs {
+			if entry.addr == addr && (entry.port == "" || entry.port == port) {
+				return true
+			}
+		}
+		for _, prefix := range m.prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, entry := range m.domains {
+		if entry.port != "" && entry.port != port {
+			continue
+		}
+		if entry.subdomainsOnly {
+			if strings.HasSuffix(host, entry.suffix) {
+				return true
+			}
+			continue
+		}
+		if host == entry.suffix || strings.HasSuffix(host, "."+entry.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
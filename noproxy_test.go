@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoProxyMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		noProxy  string
+		target   string
+		expected bool
+	}{
+		// Domains
+		{name: "exact host", noProxy: "example.com", target: "https://example.com/", expected: true},
+		{name: "subdomain of plain entry", noProxy: "example.com", target: "https://repo.example.com/", expected: true},
+		{name: "suffix is not a subdomain", noProxy: "ample.com", target: "https://example.com/", expected: false},
+		{name: "leading dot matches subdomain", noProxy: ".example.com", target: "https://repo.example.com/", expected: true},
+		{name: "leading dot skips apex", noProxy: ".example.com", target: "https://example.com/", expected: false},
+		{name: "wildcard prefix matches subdomain", noProxy: "*.example.com", target: "https://repo.example.com/", expected: true},
+		{name: "wildcard prefix skips apex", noProxy: "*.example.com", target: "https://example.com/", expected: false},
+		{name: "case insensitive", noProxy: "Example.COM", target: "https://REPO.example.com/", expected: true},
+		{name: "trailing dot in target", noProxy: "example.com", target: "https://example.com./", expected: true},
+		{name: "unrelated host", noProxy: "example.com", target: "https://example.org/", expected: false},
+
+		// Wildcard
+		{name: "star matches everything", noProxy: "*", target: "https://anything.example.org/", expected: true},
+
+		// Lists
+		{name: "second entry in list", noProxy: "localhost, .internal ,example.com", target: "https://example.com/", expected: true},
+		{name: "empty entries ignored", noProxy: ",,", target: "https://example.com/", expected: false},
+
+		// Ports
+		{name: "port matches", noProxy: "example.com:8443", target: "https://example.com:8443/", expected: true},
+		{name: "port differs", noProxy: "example.com:8443", target: "https://example.com/", expected: false},
+		{name: "default https port", noProxy: "example.com:443", target: "https://example.com/", expected: true},
+		{name: "default http port", noProxy: "example.com:80", target: "http://example.com/", expected: true},
+
+		// IPv4
+		{name: "exact IP", noProxy: "10.1.2.3", target: "http://10.1.2.3/", expected: true},
+		{name: "different IP", noProxy: "10.1.2.3", target: "http://10.1.2.4/", expected: false},
+		{name: "IP with port", noProxy: "10.1.2.3:8080", target: "http://10.1.2.3:8080/", expected: true},
+		{name: "IP with other port", noProxy: "10.1.2.3:8080", target: "http://10.1.2.3:9090/", expected: false},
+		{name: "CIDR contains IP", noProxy: "10.0.0.0/8", target: "http://10.200.3.4/", expected: true},
+		{name: "CIDR excludes IP", noProxy: "10.0.0.0/8", target: "http://11.0.0.1/", expected: false},
+		{name: "CIDR does not match hostname", noProxy: "10.0.0.0/8", target: "http://repo.internal/", expected: false},
+		{name: "unnormalized CIDR", noProxy: "192.168.1.77/24", target: "http://192.168.1.5/", expected: true},
+		{name: "IP entry does not match hostname suffix", noProxy: "1.2.3", target: "http://10.1.2.3/", expected: false},
+
+		// IPv6
+		{name: "bare IPv6 literal", noProxy: "::1", target: "http://[::1]:8080/", expected: true},
+		{name: "bracketed IPv6 literal", noProxy: "[fd00::1]", target: "https://[fd00::1]/", expected: true},
+		{name: "bracketed IPv6 with port", noProxy: "[fd00::1]:8443", target: "https://[fd00::1]:8443/", expected: true},
+		{name: "bracketed IPv6 with other port", noProxy: "[fd00::1]:8443", target: "https://[fd00::1]/", expected: false},
+		{name: "IPv6 CIDR", noProxy: "fd00::/8", target: "https://[fd12:3456::1]/", expected: true},
+		{name: "IPv6 CIDR excludes", noProxy: "fd00::/8", target: "https://[2001:db8::1]/", expected: false},
+		{name: "bracketed IPv6 CIDR", noProxy: "[fd00::]/8", target: "https://[fd12:3456::1]/", expected: true},
+		{name: "bracketed IPv6 CIDR excludes", noProxy: "[fd00::]/8", target: "https://[2001:db8::1]/", expected: false},
+		{name: "IPv4-mapped IPv6 target", noProxy: "10.0.0.0/8", target: "http://[::ffff:10.0.0.1]/", expected: true},
+
+		// Malformed entries
+		{name: "invalid CIDR ignored", noProxy: "10.0.0.0/99", target: "http://10.0.0.1/", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parseNoProxy(tt.noProxy).matches(target))
+		})
+	}
+}
+
+func TestParseNoProxyMergesLists(t *testing.T) {
+	matcher := parseNoProxy("config.internal", "env.internal,10.0.0.0/8")
+
+	for _, target := range []string{"https://config.internal/", "https://env.internal/", "http://10.1.1.1/"} {
+		parsed, err := url.Parse(target)
+		require.NoError(t, err)
+		assert.True(t, matcher.matches(parsed), target)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// pacUtilities implements the standard PAC helper functions on top of
+// dnsResolve and myIpAddress, which are provided from Go.
+const pacUtilities = `
+function isPlainHostName(host) {
+	return host.indexOf('.') < 0;
+}
+
+function dnsDomainIs(host, domain) {
+	return host.length >= domain.length &&
+		host.substring(host.length - domain.length) === domain;
+}
+
+function localHostOrDomainIs(host, hostdom) {
+	return host === hostdom || hostdom.lastIndexOf(host + '.', 0) === 0;
+}
+
+function isResolvable(host) {
+	return dnsResolve(host) !== null;
+}
+
+function convert_addr(ipchars) {
+	var bytes = ipchars.split('.');
+	return ((bytes[0] & 0xff) << 24) | ((bytes[1] & 0xff) << 16) |
+		((bytes[2] & 0xff) << 8) | (bytes[3] & 0xff);
+}
+
+function isInNet(ipaddr, pattern, maskstr) {
+	if (!/^\d+\.\d+\.\d+\.\d+$/.test(ipaddr)) {
+		ipaddr = dnsResolve(ipaddr);
+		if (ipaddr === null) {
+			return false;
+		}
+	}
+	var mask = convert_addr(maskstr);
+	return (convert_addr(ipaddr) & mask) === (convert_addr(pattern) & mask);
+}
+
+function dnsDomainLevels(host) {
+	return host.split('.').length - 1;
+}
+
+function shExpMatch(str, shexp) {
+	var re = shexp.replace(/[.+^${}()|[\]\\]/g, '\\$&').replace(/\*/g, '.*').replace(/\?/g, '.');
+	return new RegExp('^' + re + '$').test(str);
+}
+
+var pacDays = ['SUN', 'MON', 'TUE', 'WED', 'THU', 'FRI', 'SAT'];
+
+function weekdayRange(wd1, wd2, gmt) {
+	if (wd2 === 'GMT') {
+		gmt = wd2;
+		wd2 = undefined;
+	}
+	var now = new Date();
+	var today = gmt === 'GMT' ? now.getUTCDay() : now.getDay();
+	var start = pacDays.indexOf(wd1);
+	var end = wd2 === undefined ? start : pacDays.indexOf(wd2);
+	if (start < 0 || end < 0) {
+		return false;
+	}
+	return start <= end ? (today >= start && today <= end) : (today >= start || today <= end);
+}
+
+function timeRange() {
+	var args = Array.prototype.slice.call(arguments);
+	var gmt = args[args.length - 1] === 'GMT';
+	if (gmt) {
+		args.pop();
+	}
+	var now = new Date();
+	var hour = gmt ? now.getUTCHours() : now.getHours();
+	if (args.length === 1) {
+		return hour === args[0];
+	}
+	if (args.length === 2) {
+		return args[0] <= args[1] ? (hour >= args[0] && hour < args[1]) : (hour >= args[0] || hour < args[1]);
+	}
+	var minutes = hour * 60 + (gmt ? now.getUTCMinutes() : now.getMinutes());
+	var from = args[0] * 60 + args[1];
+	var to = args[2] * 60 + args[3];
+	return from <= to ? (minutes >= from && minutes < to) : (minutes >= from || minutes < to);
+}
+
+function dateRange() {
+	// Date ranges are rarely used in corporate PAC files and are not supported
+	return false;
+}
+
+function alert(message) {
+}
+`
+
+// pacScript evaluates a proxy auto-config script. The JavaScript runtime is
+// not safe for concurrent use, so evaluations are serialized.
+type pacScript struct {
+	mu     sync.Mutex
+	vm     *goja.Runtime
+	find   goja.Callable
+	source string
+}
+
+// newPACClient returns the client that fetches download.proxy.pacUrl. It
+// connects directly, without a proxy, and retries like any other request.
+func newPACClient(tlsConfig *tls.Config, policy RetryConfig, logger *Logger) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: &retryTransport{
+			base:   transport,
+			policy: policy,
+			logger: logger,
+			sleep:  time.Sleep,
+		},
+	}
+}
+
+// loadPACScript reads the PAC script from download.proxy.pacFile or fetches it
+// from download.proxy.pacUrl with the given client.
+func loadPACScript(proxy *ProxyConfig, client *http.Client) (*pacScript, error) {
+	if proxy.PacFile != "" {
+		source, err := os.ReadFile(proxy.PacFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PAC file: %w", err)
+		}
+		return newPACScript(string(source), proxy.PacFile)
+	}
+
+	resp, err := client.Get(proxy.PacURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PAC file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch PAC file: %s", resp.Status)
+	}
+
+	source, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PAC file: %w", err)
+	}
+
+	return newPACScript(string(source), redactURL(proxy.PacURL))
+}
+
+// newPACScript compiles a PAC script and looks up its FindProxyForURL function.
+func newPACScript(source, name string) (*pacScript, error) {
+	vm := goja.New()
+
+	vm.Set("dnsResolve", func(host string) goja.Value {
+		addrs, err := net.LookupIP(host)
+		if err != nil {
+			return goja.Null()
+		}
+		for _, addr := range addrs {
+			if ipv4 := addr.To4(); ipv4 != nil {
+				return vm.ToValue(ipv4.String())
+			}
+		}
+		return goja.Null()
+	})
+	vm.Set("myIpAddress", func() string {
+		return localIPAddress()
+	})
+
+	if _, err := vm.RunString(pacUtilities); err != nil {
+		return nil, fmt.Errorf("failed to initialize PAC runtime: %w", err)
+	}
+	if _, err := vm.RunScript(name, source); err != nil {
+		return nil, fmt.Errorf("failed to evaluate PAC file %s: %w", name, err)
+	}
+
+	find, ok := goja.AssertFunction(vm.Get("FindProxyForURL"))
+	if !ok {
+		return nil, fmt.Errorf("PAC file %s does not define FindProxyForURL", name)
+	}
+
+	return &pacScript{vm: vm, find: find, source: name}, nil
+}
+
+// FindProxyForURL runs the script for the target and returns its raw result.
+func (p *pacScript) FindProxyForURL(target *url.URL) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, err := p.find(goja.Undefined(), p.vm.ToValue(target.String()), p.vm.ToValue(target.Hostname()))
+	if err != nil {
+		return "", fmt.Errorf("PAC FindProxyForURL failed: %w", err)
+	}
+	return result.String(), nil
+}
+
+// parsePACResult converts a PAC result such as "PROXY a:8080; SOCKS5 b:1080; DIRECT"
+// into a proxy URL. The first entry with a supported type wins; nil means DIRECT.
+func parsePACResult(result string) (*url.URL, error) {
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var scheme string
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			// SOCKS4 and unknown types are skipped in favor of the next entry
+			continue
+		}
+
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid PAC result %q", result)
+		}
+		return &url.URL{Scheme: scheme, Host: fields[1]}, nil
+	}
+
+	return nil, fmt.Errorf("no supported proxy in PAC result %q", result)
+}
+
+// localIPAddress returns the address used for outbound connections, as PAC
+// scripts expect from myIpAddress. No packets are sent.
+func localIPAddress() string {
+	conn, err := net.Dial("udp", "192.0.2.1:80")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer conn.Close()
+
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.IP.String()
+	}
+	return "127.0.0.1"
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePACResult(t *testing.T) {
+	tests := []struct {
+		result   string
+		expected string
+		wantErr  bool
+	}{
+		{result: "DIRECT", expected: ""},
+		{result: "PROXY proxy.example.com:8080", expected: "http://proxy.example.com:8080"},
+		{result: "PROXY a:8080; PROXY b:8080", expected: "http://a:8080"},
+		{result: "HTTPS secure.example.com:443", expected: "https://secure.example.com:443"},
+		{result: "SOCKS5 gateway:1080; DIRECT", expected: "socks5://gateway:1080"},
+		{result: "SOCKS gateway:1080", expected: "socks5://gateway:1080"},
+		{result: "SOCKS4 old:1080; PROXY fallback:3128", expected: "http://fallback:3128"},
+		{result: "  proxy   spaced:3128 ", expected: "http://spaced:3128"},
+		{result: "PROXY", wantErr: true},
+		{result: "SOCKS4 old:1080", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			proxyURL, err := parsePACResult(tt.result)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expected == "" {
+				assert.Nil(t, proxyURL)
+			} else {
+				require.NotNil(t, proxyURL)
+				assert.Equal(t, tt.expected, proxyURL.String())
+			}
+		})
+	}
+}
+
+func TestPACScript(t *testing.T) {
+	script, err := newPACScript(`
+function FindProxyForURL(url, host) {
+	if (isPlainHostName(host) || dnsDomainIs(host, ".corp.example.com")) {
+		return "DIRECT";
+	}
+	if (isInNet(host, "10.0.0.0", "255.0.0.0")) {
+		return "SOCKS5 socks.example.com:1080";
+	}
+	if (shExpMatch(url, "https://*.maven.org/*")) {
+		return "PROXY central-proxy:3128";
+	}
+	return "PROXY default-proxy:8080; DIRECT";
+}`, "test.pac")
+	require.NoError(t, err)
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{target: "http://intranet/", expected: "DIRECT"},
+		{target: "https://repo.corp.example.com/", expected: "DIRECT"},
+		{target: "http://10.2.3.4/", expected: "SOCKS5 socks.example.com:1080"},
+		{target: "https://repo1.maven.org/maven2/", expected: "PROXY central-proxy:3128"},
+		{target: "https://example.org/", expected: "PROXY default-proxy:8080; DIRECT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+
+			result, err := script.FindProxyForURL(target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("requires FindProxyForURL", func(t *testing.T) {
+		_, err := newPACScript(`var x = 1;`, "empty.pac")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not define FindProxyForURL")
+	})
+
+	t.Run("reports syntax errors", func(t *testing.T) {
+		_, err := newPACScript(`function FindProxyForURL(url, host) {`, "broken.pac")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to evaluate PAC file")
+	})
+}
+
+func TestPACProxyRouting(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(testMetadata))
+	}))
+	defer proxy.Close()
+
+	pacFile := filepath.Join(t.TempDir(), "proxy.pac")
+	pac := `function FindProxyForURL(url, host) {
+	if (dnsDomainIs(host, ".example.com")) {
+		return "PROXY ` + proxy.Listener.Addr().String() + `";
+	}
+	return "DIRECT";
+}`
+	require.NoError(t, os.WriteFile(pacFile, []byte(pac), 0644))
+
+	clearProxyEnv(t)
+
+	t.Run("routes requests through PAC file result", func(t *testing.T) {
+		proxied = nil
+		download := &DownloadConfig{
+			BaseURL: "http://repo.example.com/moderne-cli",
+			Proxy:   &ProxyConfig{PacFile: pacFile},
+		}
+		repository, err := NewRepository(download, NewLogger())
+		require.NoError(t, err)
+
+		version, err := repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", version)
+		assert.Equal(t, []string{"http://repo.example.com/moderne-cli/maven-metadata.xml"}, proxied)
+	})
+
+	t.Run("loads PAC script from URL", func(t *testing.T) {
+		proxied = nil
+		pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+			w.Write([]byte(pac))
+		}))
+		defer pacServer.Close()
+
+		download := &DownloadConfig{
+			BaseURL: "http://repo.example.com/moderne-cli",
+			Proxy:   &ProxyConfig{PacURL: pacServer.URL + "/proxy.pac"},
+		}
+		repository, err := NewRepository(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Len(t, proxied, 1)
+	})
+
+	t.Run("retries fetching the PAC script", func(t *testing.T) {
+		proxied = nil
+		calls := 0
+		pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(pac))
+		}))
+		defer pacServer.Close()
+
+		download := &DownloadConfig{
+			BaseURL: "http://repo.example.com/moderne-cli",
+			Proxy:   &ProxyConfig{PacURL: pacServer.URL + "/proxy.pac"},
+			Retry:   fastRetry,
+		}
+		repository, err := NewRepository(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Len(t, proxied, 1)
+	})
+
+	t.Run("connects directly for DIRECT result", func(t *testing.T) {
+		proxied = nil
+		direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(testMetadata))
+		}))
+		defer direct.Close()
+
+		download := &DownloadConfig{BaseURL: direct.URL, Proxy: &ProxyConfig{PacFile: pacFile}}
+		repository, err := NewRepository(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Empty(t, proxied)
+	})
+}
+
+func TestSOCKS5Proxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testMetadata))
+	}))
+	defer target.Close()
+
+	socks := startSOCKS5Server(t, "user", "pass")
+	clearProxyEnv(t)
+
+	t.Run("connects through SOCKS5 with credentials", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: target.URL,
+			Proxy:   &ProxyConfig{URL: "socks5h://" + socks.addr, Username: "user", Password: "pass"},
+			Retry:   &RetryConfig{Attempts: 1},
+		}
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+
+		version, err := FetchLatestVersion(target.URL, client)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", version)
+		assert.Equal(t, 1, socks.connections())
+	})
+
+	t.Run("fails with wrong credentials", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: target.URL,
+			Proxy:   &ProxyConfig{URL: "socks5://wrong:creds@" + socks.addr},
+			Retry:   &RetryConfig{Attempts: 1},
+		}
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = FetchLatestVersion(target.URL, client)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unsupported proxy scheme", func(t *testing.T) {
+		download := &DownloadConfig{Proxy: &ProxyConfig{URL: "socks4://" + socks.addr}}
+		_, err := newHTTPClient(download, NewLogger())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported proxy scheme")
+	})
+}
+
+// testSOCKS5Server is a minimal SOCKS5 proxy requiring username/password auth.
+type testSOCKS5Server struct {
+	addr    string
+	connect chan struct{}
+}
+
+func (s *testSOCKS5Server) connections() int {
+	return len(s.connect)
+}
+
+func startSOCKS5Server(t *testing.T, username, password string) *testSOCKS5Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testSOCKS5Server{addr: listener.Addr().String(), connect: make(chan struct{}, 100)}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn, username, password)
+		}
+	}()
+
+	return server
+}
+
+func (s *testSOCKS5Server) handle(conn net.Conn, username, password string) {
+	defer conn.Close()
+
+	// Greeting: version, method count, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2}) // username/password
+
+	// Username/password sub-negotiation (RFC 1929)
+	readField := func() string {
+		length := make([]byte, 1)
+		io.ReadFull(conn, length)
+		value := make([]byte, length[0])
+		io.ReadFull(conn, value)
+		return string(value)
+	}
+	io.ReadFull(conn, make([]byte, 1))
+	user, pass := readField(), readField()
+	if user != username || pass != password {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	// Connect request: version, command, reserved, address type
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		host = readField()
+	case 4:
+		ip := make([]byte, 16)
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	}
+	portBytes := make([]byte, 2)
+	io.ReadFull(conn, portBytes)
+	port := binary.BigEndian.Uint16(portBytes)
+
+	upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+
+	s.connect <- struct{}{}
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
@@ -30,6 +30,9 @@ func (i *Installer) runPostInstallCommands() error {
 
 	for _, cmdLine := range commands {
 		if err := i.executeCommand(cmdLine); err != nil {
+			if i.strictCommands {
+				return fmt.Errorf("command '%s' failed: %w", cmdLine, err)
+			}
 			i.logger.Warning("Command '%s' failed: %v", cmdLine, err)
 		} else {
 			i.logger.Success("Executed: %s", cmdLine)
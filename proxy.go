@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxySettings decides which proxy, if any, each request goes through.
+//
+// Precedence: a PAC script from download.proxy.pacUrl or pacFile is evaluated
+// for every request. Otherwise download.proxy.url from config.yaml wins over the
+// environment for both http and https requests. Without either, HTTPS_PROXY and
+// HTTP_PROXY (or their lowercase forms) are used by request scheme. Hosts listed
+// in either download.proxy.noProxy or NO_PROXY always bypass the proxy.
+type proxySettings struct {
+	httpProxy  *url.URL
+	httpsProxy *url.URL
+	pac        *pacScript
+	pacUser    *url.Userinfo
+	noProxy    *noProxyMatcher
+	source     string
+}
+
+// resolveProxySettings merges the configured proxy with the environment.
+// The PAC client is used to fetch a PAC script from download.proxy.pacUrl.
+// It returns nil when no proxy applies at all.
+func resolveProxySettings(proxy *ProxyConfig, getenv func(string) string, pacClient *http.Client) (*proxySettings, error) {
+	envNoProxy := firstEnv(getenv, "NO_PROXY", "no_proxy")
+	configNoProxy := ""
+	if proxy != nil {
+		configNoProxy = proxy.NoProxy
+	}
+	settings := &proxySettings{
+		noProxy: parseNoProxy(configNoProxy, envNoProxy),
+	}
+
+	if proxy != nil && (proxy.PacURL != "" || proxy.PacFile != "") {
+		script, err := loadPACScript(proxy, pacClient)
+		if err != nil {
+			return nil, err
+		}
+
+		settings.pac = script
+		if proxy.Username != "" {
+			settings.pacUser = url.UserPassword(proxy.Username, proxy.Password)
+		}
+		settings.source = "PAC " + script.source
+		return settings, nil
+	}
+
+	if proxy != nil && proxy.URL != "" {
+		proxyURL, err := parseProxyURL(proxy.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+
+		// Add authentication if provided
+		if proxy.Username != "" {
+			proxyURL.User = url.UserPassword(proxy.Username, proxy.Password)
+		}
+
+		settings.httpProxy = proxyURL
+		settings.httpsProxy = proxyURL
+		settings.source = "config"
+		return settings, nil
+	}
+
+	if value := firstEnv(getenv, "HTTP_PROXY", "http_proxy"); value != "" {
+		proxyURL, err := parseProxyURL(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP_PROXY: %w", err)
+		}
+		settings.httpProxy = proxyURL
+	}
+	if value := firstEnv(getenv, "HTTPS_PROXY", "https_proxy"); value != "" {
+		proxyURL, err := parseProxyURL(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTPS_PROXY: %w", err)
+		}
+		settings.httpsProxy = proxyURL
+	}
+
+	if settings.httpProxy == nil && settings.httpsProxy == nil {
+		return nil, nil
+	}
+	settings.source = "environment"
+	return settings, nil
+}
+
+// proxyFor returns the proxy for a request, or nil to connect directly.
+func (p *proxySettings) proxyFor(req *http.Request) (*url.URL, error) {
+	if p.noProxy.matches(req.URL) {
+		return nil, nil
+	}
+	if p.pac != nil {
+		result, err := p.pac.FindProxyForURL(req.URL)
+		if err != nil {
+			return nil, err
+		}
+		proxyURL, err := parsePACResult(result)
+		if proxyURL != nil && p.pacUser != nil {
+			proxyURL.User = p.pacUser
+		}
+		return proxyURL, err
+	}
+	if req.URL.Scheme == "https" {
+		return p.httpsProxy, nil
+	}
+	return p.httpProxy, nil
+}
+
+// describe summarizes the proxy settings for logging, with credentials redacted.
+func (p *proxySettings) describe() string {
+	if p.pac != nil {
+		return p.source
+	}
+
+	var parts []string
+	if p.httpProxy != nil && p.httpProxy == p.httpsProxy {
+		parts = append(parts, p.httpProxy.Redacted())
+	} else {
+		if p.httpProxy != nil {
+			parts = append(parts, "http="+p.httpProxy.Redacted())
+		}
+		if p.httpsProxy != nil {
+			parts = append(parts, "https="+p.httpsProxy.Redacted())
+		}
+	}
+	return fmt.Sprintf("%s (from %s)", strings.Join(parts, ", "), p.source)
+}
+
+// parseProxyURL parses a proxy address, assuming http:// when no scheme is given
+// as curl and Go do for environment variables. SOCKS5 proxies are supported
+// with socks5:// and socks5h:// (both resolve host names on the proxy).
+func parseProxyURL(value string) (*url.URL, error) {
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", redactURL(value))
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https, socks5 or socks5h)", proxyURL.Scheme)
+	}
+
+	return proxyURL, nil
+}
+
+// firstEnv returns the first non-empty environment variable among names.
+func firstEnv(getenv func(string) string, names ...string) string {
+	for _, name := range names {
+		if value := getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
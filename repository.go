@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Repository resolves artifacts from an ordered list of mirrors, failing over
+// to the next mirror when one is unavailable. The mirror that succeeds first
+// is remembered and tried first for the rest of the run.
+type Repository struct {
+	mirrors []*mirror
+	active  *mirror
+	channel string
+	cache   *metadataCache
+	logger  *Logger
+}
+
+// mirror is a single download source with its own HTTP client.
+type mirror struct {
+	name           string
+	baseURL        string
+	repositoryType string
+	client         *http.Client
+}
+
+// NewRepository creates a Repository for the configured mirrors.
+func NewRepository(download *DownloadConfig, logger *Logger) (*Repository, error) {
+	repository := &Repository{channel: download.ChannelMode(), logger: logger}
+
+	for _, mirrorConfig := range download.MirrorList() {
+		settings := *download
+		settings.BaseURL = mirrorConfig.BaseURL
+		settings.Auth = mirrorConfig.Auth
+		settings.Proxy = mirrorConfig.Proxy
+
+		client, err := newHTTPClient(&settings, logger)
+		if err != nil {
+			return nil, fmt.Errorf("mirror %s: %w", mirrorConfig.Name, err)
+		}
+
+		repository.mirrors = append(repository.mirrors, &mirror{
+			name:           mirrorConfig.Name,
+			baseURL:        strings.TrimRight(mirrorConfig.BaseURL, "/"),
+			repositoryType: mirrorConfig.RepositoryTypeMode(),
+			client:         client,
+		})
+	}
+
+	if len(repository.mirrors) == 0 {
+		return nil, fmt.Errorf("no download mirrors configured")
+	}
+
+	return repository, nil
+}
+
+// LatestVersion returns the newest version in the configured channel from the
+// first available mirror.
+func (r *Repository) LatestVersion() (string, error) {
+	metadata, err := r.Metadata()
+	if err != nil {
+		return "", err
+	}
+	return latestVersion(metadata, r.channel)
+}
+
+// Metadata fetches the list of published versions from the first available
+// mirror, in the shape of maven-metadata.xml.
+func (r *Repository) Metadata() (*MavenMetadata, error) {
+	var metadata *MavenMetadata
+	err := r.try(func(m *mirror) error {
+		fetched, err := r.mirrorMetadata(m)
+		if err != nil {
+			return err
+		}
+		metadata = fetched
+		return nil
+	})
+	return metadata, err
+}
+
+// ResolveVersion returns the highest release matching a version constraint
+// such as "3.57" or ">=3.50 <4" from the versions in maven-metadata.xml.
+func (r *Repository) ResolveVersion(expression string) (string, error) {
+	constraint, err := parseConstraint(expression)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		return "", err
+	}
+	return constraint.resolve(availableVersions(metadata), r.channel)
+}
+
+// Active returns the mirror that last served a request, or nil if none has.
+func (r *Repository) Active() *mirror {
+	return r.active
+}
+
+// Describe lists the mirrors in the order they are tried.
+func (r *Repository) Describe() string {
+	var names []string
+	for _, m := range r.ordered() {
+		names = append(names, fmt.Sprintf("%s (%s)", m.name, redactURL(m.baseURL)))
+	}
+	return strings.Join(names, ", ")
+}
+
+// try runs fn against each mirror in turn until one succeeds.
+// Verification failures are returned immediately: a mirror serving a tampered
+// artifact is an error, not an outage.
+func (r *Repository) try(fn func(m *mirror) error) error {
+	var errs []error
+
+	for _, m := range r.ordered() {
+		err := fn(m)
+		if err == nil {
+			if r.active != m && len(r.mirrors) > 1 {
+				r.logger.Info("Using mirror: %s", m.name)
+			}
+			r.active = m
+			return nil
+		}
+
+		var verification *verificationError
+		if errors.As(err, &verification) {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		if len(r.mirrors) > 1 {
+			r.logger.Warning("Mirror %s failed: %v", m.name, err)
+		}
+	}
+
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return fmt.Errorf("all mirrors failed: %w", errors.Join(errs...))
+}
+
+// mirrorMetadata looks up the mirror's versions according to its repository type.
+// Maven mirrors without maven-metadata.xml, such as plain HTTP servers, are
+// asked for versions.json or a directory listing instead. maven-metadata.xml
+// goes through the metadata cache when one is set.
+func (r *Repository) mirrorMetadata(m *mirror) (*MavenMetadata, error) {
+	if r.cache != nil && r.cache.offline && m.repositoryType != RepositoryMaven {
+		return nil, fmt.Errorf("versions from a %s repository are not cached; specify an exact -version to install offline", m.repositoryType)
+	}
+
+	switch m.repositoryType {
+	case RepositoryArtifactory:
+		return fetchArtifactoryVersions(m.baseURL, m.client)
+	case RepositoryNexus:
+		return fetchNexusVersions(m.baseURL, m.client)
+	case RepositoryHTTP:
+		return discoverVersions(m.baseURL, m.client)
+	}
+
+	var metadata *MavenMetadata
+	var err error
+	if r.cache != nil && !strings.HasPrefix(m.baseURL, "file:") {
+		metadata, err = r.cache.fetch(m.baseURL, m.client)
+	} else {
+		metadata, err = FetchMetadata(m.baseURL, m.client)
+	}
+	var status *metadataStatusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		r.logger.Info("No maven-metadata.xml at %s, looking for a version listing", redactURL(m.baseURL))
+		return discoverVersions(m.baseURL, m.client)
+	}
+	return metadata, err
+}
+
+// ordered returns the mirrors with the remembered one first.
+func (r *Repository) ordered() []*mirror {
+	if r.active == nil {
+		return r.mirrors
+	}
+
+	ordered := []*mirror{r.active}
+	for _, m := range r.mirrors {
+		if m != r.active {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered
+}
+
+// verificationError marks an artifact that failed checksum or signature verification.
+type verificationError struct {
+	err error
+}
+
+func (e *verificationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *verificationError) Unwrap() error {
+	return e.err
+}
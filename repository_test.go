@@ -0,0 +1,181 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <versioning>
+    <latest>1.0.0</latest>
+    <release>1.0.0</release>
+  </versioning>
+</metadata>`
+
+func TestDownloadConfig_MirrorList(t *testing.T) {
+	t.Run("uses baseUrl without mirrors", func(t *testing.T) {
+		proxy := &ProxyConfig{URL: "http://proxy:8080"}
+		config := DownloadConfig{BaseURL: "http://example.com", Proxy: proxy}
+
+		mirrors := config.MirrorList()
+		require.Len(t, mirrors, 1)
+		assert.Equal(t, "default", mirrors[0].Name)
+		assert.Equal(t, "http://example.com", mirrors[0].BaseURL)
+		assert.Equal(t, proxy, mirrors[0].Proxy)
+	})
+
+	t.Run("mirrors inherit download proxy unless overridden", func(t *testing.T) {
+		shared := &ProxyConfig{URL: "http://shared:8080"}
+		own := &ProxyConfig{URL: "http://own:8080"}
+		config := DownloadConfig{
+			BaseURL: "http://ignored.example.com",
+			Proxy:   shared,
+			Mirrors: []MirrorConfig{
+				{Name: "office", BaseURL: "http://office.example.com", Proxy: own},
+				{BaseURL: "http://central.example.com"},
+			},
+		}
+
+		mirrors := config.MirrorList()
+		require.Len(t, mirrors, 2)
+		assert.Equal(t, "office", mirrors[0].Name)
+		assert.Equal(t, own, mirrors[0].Proxy)
+		assert.Equal(t, "mirror-2", mirrors[1].Name)
+		assert.Equal(t, shared, mirrors[1].Proxy)
+	})
+}
+
+func TestRepository(t *testing.T) {
+	newRepository := func(t *testing.T, urls ...string) *Repository {
+		download := &DownloadConfig{Retry: &RetryConfig{Attempts: 1}}
+		for _, u := range urls {
+			download.Mirrors = append(download.Mirrors, MirrorConfig{BaseURL: u})
+		}
+		repository, err := NewRepository(download, NewLogger())
+		require.NoError(t, err)
+		return repository
+	}
+
+	t.Run("fails over to next mirror and remembers it", func(t *testing.T) {
+		downCalls := 0
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downCalls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer down.Close()
+
+		up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(testMetadata))
+		}))
+		defer up.Close()
+
+		repository := newRepository(t, down.URL, up.URL)
+
+		version, err := repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", version)
+		require.NotNil(t, repository.Active())
+		assert.Equal(t, up.URL, repository.Active().baseURL)
+
+		// The working mirror is tried first from now on
+		_, err = repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, 1, downCalls)
+	})
+
+	t.Run("reports every mirror when all fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		repository := newRepository(t, server.URL+"/a", server.URL+"/b")
+
+		_, err := repository.LatestVersion()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "all mirrors failed")
+		assert.Contains(t, err.Error(), "mirror-1")
+		assert.Contains(t, err.Error(), "mirror-2")
+		assert.Nil(t, repository.Active())
+	})
+
+	t.Run("does not fail over on verification errors", func(t *testing.T) {
+		tampered := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/1.0.0/moderne-cli-1.0.0.jar":
+				w.Write([]byte("tampered"))
+			case "/1.0.0/moderne-cli-1.0.0.jar.sha1":
+				w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer tampered.Close()
+
+		fallbackCalled := false
+		fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fallbackCalled = true
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer fallback.Close()
+
+		binDir := filepath.Join(t.TempDir(), ".moderne", "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			repository:  newRepository(t, tampered.URL, fallback.URL),
+			logger:      NewLogger(),
+		}
+
+		err := installer.downloadJAR()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		assert.False(t, fallbackCalled, "fallback mirror should not have been called")
+	})
+
+	t.Run("downloads JAR from fallback mirror", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer down.Close()
+
+		up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/1.0.0/moderne-cli-1.0.0.jar" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("fake jar content"))
+		}))
+		defer up.Close()
+
+		binDir := filepath.Join(t.TempDir(), ".moderne", "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0755))
+
+		installer := &Installer{
+			version:     "1.0.0",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+			jarFileName: "moderne-cli-1.0.0.jar",
+			repository:  newRepository(t, down.URL, up.URL),
+			logger:      NewLogger(),
+		}
+
+		err := installer.downloadJAR()
+		require.NoError(t, err)
+		assert.FileExists(t, installer.jarPath)
+		assert.Equal(t, up.URL, installer.repository.Active().baseURL)
+	})
+}
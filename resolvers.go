@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Repository types for download.repositoryType. They decide how versions are
+// looked up; JARs are always downloaded from the Maven layout under baseUrl.
+const (
+	RepositoryMaven       = "maven"
+	RepositoryArtifactory = "artifactory"
+	RepositoryNexus       = "nexus"
+	RepositoryHTTP        = "http"
+)
+
+// maxNexusPages bounds how many result pages of a Nexus search are read.
+const maxNexusPages = 100
+
+// artifactCoordinates locates the CLI in a repository manager, derived from a
+// Maven-layout base URL such as <root>/<repository>/io/moderne/moderne-cli.
+type artifactCoordinates struct {
+	root       string
+	repository string
+	groupID    string
+	artifactID string
+}
+
+// parseCoordinates splits baseURL at the path segment named context
+// ("artifactory" or "repository"). Without that segment the server root is used.
+func parseCoordinates(baseURL, context string) (artifactCoordinates, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return artifactCoordinates{}, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	rootSegments := 0
+	for i, segment := range segments {
+		if segment == context {
+			rootSegments = i + 1
+			break
+		}
+	}
+
+	// <repository>/<group path...>/<artifactId>
+	rest := segments[rootSegments:]
+	if len(rest) < 3 {
+		return artifactCoordinates{}, fmt.Errorf("cannot derive repository and coordinates from %s; expected .../<repository>/<group>/<artifactId>", redactURL(baseURL))
+	}
+
+	root := *parsed
+	root.Path = "/" + strings.Join(segments[:rootSegments], "/")
+	root.RawQuery, root.Fragment = "", ""
+
+	return artifactCoordinates{
+		root:       strings.TrimRight(root.String(), "/"),
+		repository: rest[0],
+		groupID:    strings.Join(rest[1:len(rest)-1], "."),
+		artifactID: rest[len(rest)-1],
+	}, nil
+}
+
+// fetchArtifactoryVersions lists versions with the Artifactory search API.
+// If the version search is unavailable, the latestVersion search is used to
+// find at least the newest release.
+func fetchArtifactoryVersions(baseURL string, client *http.Client) (*MavenMetadata, error) {
+	coordinates, err := parseCoordinates(baseURL, "artifactory")
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"g":     {coordinates.groupID},
+		"a":     {coordinates.artifactID},
+		"repos": {coordinates.repository},
+	}
+
+	var result struct {
+		Results []struct {
+			Version string `json:"version"`
+		} `json:"results"`
+	}
+	body, err := fetchAPI(client, coordinates.root+"/api/search/versions?"+query.Encode())
+	if err == nil {
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse Artifactory response: %w", err)
+		}
+		metadata := &MavenMetadata{GroupID: coordinates.groupID, ArtifactID: coordinates.artifactID}
+		for _, r := range result.Results {
+			metadata.Versioning.Versions = append(metadata.Versioning.Versions, r.Version)
+		}
+		if len(metadata.Versioning.Versions) > 0 {
+			return metadata, nil
+		}
+	}
+
+	body, latestErr := fetchAPI(client, coordinates.root+"/api/search/latestVersion?"+query.Encode())
+	if latestErr != nil {
+		return nil, errors.Join(err, latestErr)
+	}
+	version := strings.TrimSpace(string(body))
+	if version == "" {
+		return nil, fmt.Errorf("no version found in Artifactory repository %s", coordinates.repository)
+	}
+	return &MavenMetadata{
+		GroupID:    coordinates.groupID,
+		ArtifactID: coordinates.artifactID,
+		Versioning: Versioning{Latest: version, Release: version},
+	}, nil
+}
+
+// fetchNexusVersions lists versions with the Nexus 3 search API, following
+// continuation tokens across result pages.
+func fetchNexusVersions(baseURL string, client *http.Client) (*MavenMetadata, error) {
+	coordinates, err := parseCoordinates(baseURL, "repository")
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &MavenMetadata{GroupID: coordinates.groupID, ArtifactID: coordinates.artifactID}
+	seen := map[string]bool{}
+	token := ""
+	for page := 0; page < maxNexusPages; page++ {
+		query := url.Values{
+			"repository":       {coordinates.repository},
+			"maven.groupId":    {coordinates.groupID},
+			"maven.artifactId": {coordinates.artifactID},
+			"maven.extension":  {"jar"},
+		}
+		if token != "" {
+			query.Set("continuationToken", token)
+		}
+
+		body, err := fetchAPI(client, coordinates.root+"/service/rest/v1/search?"+query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Items []struct {
+				Version string `json:"version"`
+			} `json:"items"`
+			ContinuationToken *string `json:"continuationToken"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse Nexus response: %w", err)
+		}
+
+		// Components with classifiers appear as separate items of the same version
+		for _, item := range result.Items {
+			if item.Version != "" && !seen[item.Version] {
+				seen[item.Version] = true
+				metadata.Versioning.Versions = append(metadata.Versioning.Versions, item.Version)
+			}
+		}
+
+		if result.ContinuationToken == nil || *result.ContinuationToken == "" {
+			break
+		}
+		token = *result.ContinuationToken
+	}
+
+	if len(metadata.Versioning.Versions) == 0 {
+		return nil, fmt.Errorf("no version found in Nexus repository %s", coordinates.repository)
+	}
+	return metadata, nil
+}
+
+// fetchAPI performs a GET against a repository manager API.
+func fetchAPI(client *http.Client, apiURL string) ([]byte, error) {
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", redactURL(apiURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query %s: %s", redactURL(apiURL), resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxListingSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", redactURL(apiURL), err)
+	}
+	return body, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	t.Run("artifactory context path", func(t *testing.T) {
+		coordinates, err := parseCoordinates("https://acme.jfrog.io/artifactory/libs-release/io/moderne/moderne-cli", "artifactory")
+		require.NoError(t, err)
+		assert.Equal(t, artifactCoordinates{
+			root:       "https://acme.jfrog.io/artifactory",
+			repository: "libs-release",
+			groupID:    "io.moderne",
+			artifactID: "moderne-cli",
+		}, coordinates)
+	})
+
+	t.Run("nexus repository path", func(t *testing.T) {
+		coordinates, err := parseCoordinates("https://nexus.example.com/nexus/repository/maven-releases/io/moderne/moderne-cli", "repository")
+		require.NoError(t, err)
+		assert.Equal(t, "https://nexus.example.com/nexus/repository", coordinates.root)
+		assert.Equal(t, "maven-releases", coordinates.repository)
+		assert.Equal(t, "io.moderne", coordinates.groupID)
+	})
+
+	t.Run("server root without context segment", func(t *testing.T) {
+		coordinates, err := parseCoordinates("https://repo.example.com/libs-release/io/moderne/moderne-cli", "artifactory")
+		require.NoError(t, err)
+		assert.Equal(t, "https://repo.example.com", coordinates.root)
+		assert.Equal(t, "libs-release", coordinates.repository)
+	})
+
+	t.Run("rejects URLs without coordinates", func(t *testing.T) {
+		_, err := parseCoordinates("https://repo.example.com/artifactory/libs-release", "artifactory")
+		assert.ErrorContains(t, err, "cannot derive repository")
+	})
+}
+
+func TestFetchArtifactoryVersions(t *testing.T) {
+	t.Run("lists versions with the version search", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/artifactory/api/search/versions", r.URL.Path)
+			assert.Equal(t, "io.moderne", r.URL.Query().Get("g"))
+			assert.Equal(t, "moderne-cli", r.URL.Query().Get("a"))
+			assert.Equal(t, "libs-release", r.URL.Query().Get("repos"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"results": [
+				{"version": "3.58.0-rc.1", "integration": false},
+				{"version": "3.57.10", "integration": false},
+				{"version": "3.57.9", "integration": false}
+			]}`))
+		}))
+		defer server.Close()
+
+		metadata, err := fetchArtifactoryVersions(server.URL+"/artifactory/libs-release/io/moderne/moderne-cli", http.DefaultClient)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"3.58.0-rc.1", "3.57.10", "3.57.9"}, metadata.Versioning.Versions)
+	})
+
+	t.Run("falls back to latestVersion search", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/artifactory/api/search/latestVersion":
+				w.Write([]byte("3.57.10"))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer server.Close()
+
+		metadata, err := fetchArtifactoryVersions(server.URL+"/artifactory/libs-release/io/moderne/moderne-cli", http.DefaultClient)
+		require.NoError(t, err)
+		version, err := latestVersion(metadata, ChannelStable)
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", version)
+	})
+
+	t.Run("returns error when both searches fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := fetchArtifactoryVersions(server.URL+"/artifactory/libs-release/io/moderne/moderne-cli", http.DefaultClient)
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+}
+
+func TestFetchNexusVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repository/service/rest/v1/search", r.URL.Path)
+		assert.Equal(t, "maven-releases", r.URL.Query().Get("repository"))
+		assert.Equal(t, "io.moderne", r.URL.Query().Get("maven.groupId"))
+		assert.Equal(t, "moderne-cli", r.URL.Query().Get("maven.artifactId"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Write([]byte(`{"items": [{"version": "3.57.9"}, {"version": "3.57.9"}], "continuationToken": "page2"}`))
+			return
+		}
+		assert.Equal(t, "page2", r.URL.Query().Get("continuationToken"))
+		w.Write([]byte(`{"items": [{"version": "3.57.10"}], "continuationToken": null}`))
+	}))
+	defer server.Close()
+
+	metadata, err := fetchNexusVersions(server.URL+"/repository/maven-releases/io/moderne/moderne-cli", http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.57.9", "3.57.10"}, metadata.Versioning.Versions)
+}
+
+func TestRepositoryType(t *testing.T) {
+	t.Run("mode defaults to maven", func(t *testing.T) {
+		for value, expected := range map[string]string{"": RepositoryMaven, "Nexus": RepositoryNexus, "artifactory": RepositoryArtifactory, "http": RepositoryHTTP, "bogus": RepositoryMaven} {
+			mirror := MirrorConfig{RepositoryType: value}
+			assert.Equal(t, expected, mirror.RepositoryTypeMode(), value)
+		}
+	})
+
+	t.Run("mirrors inherit the repository type", func(t *testing.T) {
+		config := DownloadConfig{
+			RepositoryType: RepositoryNexus,
+			Mirrors: []MirrorConfig{
+				{BaseURL: "http://nexus.example.com"},
+				{BaseURL: "http://central.example.com", RepositoryType: RepositoryMaven},
+			},
+		}
+
+		mirrors := config.MirrorList()
+		assert.Equal(t, RepositoryNexus, mirrors[0].RepositoryType)
+		assert.Equal(t, RepositoryMaven, mirrors[1].RepositoryType)
+	})
+
+	t.Run("latest version from Nexus ignores stale metadata", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repository/maven-releases/io/moderne/moderne-cli/maven-metadata.xml":
+				w.Write([]byte(testMetadata))
+			case "/repository/service/rest/v1/search":
+				w.Write([]byte(`{"items": [{"version": "3.57.9"}, {"version": "3.58.0-rc.1"}, {"version": "3.57.10"}]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		repository, err := NewRepository(&DownloadConfig{
+			BaseURL:        server.URL + "/repository/maven-releases/io/moderne/moderne-cli",
+			RepositoryType: RepositoryNexus,
+		}, NewLogger())
+		require.NoError(t, err)
+
+		latest, err := repository.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", latest)
+	})
+}
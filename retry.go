@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default retry settings applied when download.retry is not configured.
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = time.Second
+	defaultRetryMaxDelay  = 30 * time.Second
+	defaultRetryJitter    = 0.2
+)
+
+// backoff returns how long to wait before the retry following the given attempt.
+// A positive retryAfter from the server takes precedence over the computed delay,
+// but is capped at MaxDelay so a server cannot stall the installer indefinitely.
+func (r RetryConfig) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, r.MaxDelay)
+	}
+
+	delay := r.BaseDelay
+	for n := 1; n < attempt && delay < r.MaxDelay; n++ {
+		delay *= 2
+	}
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter > 0 {
+		// Spread delays by +/- jitter to avoid synchronized retries across a fleet
+		factor := 1 + r.Jitter*(2*rand.Float64()-1)
+		delay = time.Duration(float64(delay) * factor)
+	}
+
+	return delay
+}
+
+// retryTransport retries idempotent requests that fail with transient errors.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryConfig
+	logger *Logger
+	sleep  func(time.Duration)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		reason := retryReason(req, resp, err)
+		if reason == "" || attempt >= t.policy.Attempts {
+			return resp, err
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		delay := t.policy.backoff(attempt, retryAfter)
+		t.logger.Warning("Request to %s failed (%s), retrying in %s (attempt %d/%d)",
+			redactURL(req.URL.String()), reason, delay.Round(time.Millisecond), attempt+1, t.policy.Attempts)
+		t.sleep(delay)
+	}
+}
+
+// retryReason describes why a request should be retried, or returns "" if it should not.
+func retryReason(req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		if req.Context().Err() != nil || errors.Is(err, context.Canceled) {
+			return ""
+		}
+		// Certificate problems will not fix themselves on retry
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return ""
+		}
+		return err.Error()
+	}
+
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return resp.Status
+	}
+
+	return ""
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetry keeps retry delays negligible in tests.
+var fastRetry = &RetryConfig{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("applies defaults when unset", func(t *testing.T) {
+		config := DownloadConfig{}
+		policy := config.RetryPolicy()
+
+		assert.Equal(t, defaultRetryAttempts, policy.Attempts)
+		assert.Equal(t, defaultRetryBaseDelay, policy.BaseDelay)
+		assert.Equal(t, defaultRetryMaxDelay, policy.MaxDelay)
+		assert.Equal(t, defaultRetryJitter, policy.Jitter)
+	})
+
+	t.Run("keeps configured values", func(t *testing.T) {
+		config := DownloadConfig{Retry: &RetryConfig{Attempts: 5, BaseDelay: 2 * time.Second, MaxDelay: time.Minute, Jitter: 0.5}}
+		policy := config.RetryPolicy()
+
+		assert.Equal(t, 5, policy.Attempts)
+		assert.Equal(t, 2*time.Second, policy.BaseDelay)
+		assert.Equal(t, time.Minute, policy.MaxDelay)
+		assert.Equal(t, 0.5, policy.Jitter)
+	})
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	policy := RetryConfig{Attempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{attempt: 1, expected: time.Second},
+		{attempt: 2, expected: 2 * time.Second},
+		{attempt: 3, expected: 4 * time.Second},
+		{attempt: 4, expected: 5 * time.Second},
+		{attempt: 9, expected: 5 * time.Second},
+		{attempt: 1, retryAfter: 3 * time.Second, expected: 3 * time.Second},
+		{attempt: 1, retryAfter: 7 * time.Second, expected: 5 * time.Second},
+		{attempt: 1, retryAfter: 24 * time.Hour, expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.backoff(tt.attempt, tt.retryAfter))
+	}
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		jittered := RetryConfig{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 0.2}
+		for n := 0; n < 100; n++ {
+			delay := jittered.backoff(1, 0)
+			assert.GreaterOrEqual(t, delay, 800*time.Millisecond)
+			assert.LessOrEqual(t, delay, 1200*time.Millisecond)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, float64(time.Minute), float64(parseRetryAfter(future)), float64(2*time.Second))
+}
+
+func TestRetryTransport(t *testing.T) {
+	newTransport := func(attempts int) (*retryTransport, *[]time.Duration) {
+		var sleeps []time.Duration
+		return &retryTransport{
+			base:   http.DefaultTransport,
+			policy: RetryConfig{Attempts: attempts, BaseDelay: time.Second, MaxDelay: time.Minute},
+			logger: NewLogger(),
+			sleep:  func(d time.Duration) { sleeps = append(sleeps, d) },
+		}, &sleeps
+	}
+
+	t.Run("retries transient status codes", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		transport, sleeps := newTransport(3)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 3, calls)
+		assert.Len(t, *sleeps, 2)
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.Header().Set("Retry-After", "12")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		transport, sleeps := newTransport(3)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, []time.Duration{12 * time.Second}, *sleeps)
+	})
+
+	t.Run("returns last response when attempts are exhausted", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		transport, _ := newTransport(2)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("does not retry permanent failures", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		transport, sleeps := newTransport(3)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("retries connection errors", func(t *testing.T) {
+		transport, sleeps := newTransport(3)
+		transport.base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection reset by peer")
+		})
+
+		_, err := (&http.Client{Transport: transport}).Get("http://example.invalid/")
+		assert.Error(t, err)
+		assert.Len(t, *sleeps, 2)
+	})
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed dotted version such as 3.57.9 or 3.58.0-rc.1.
+// Any number of numeric components is accepted; missing ones compare as zero.
+type semver struct {
+	parts      []int
+	prerelease string
+}
+
+// parseSemver parses a version string, ignoring a leading "v" and build metadata.
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+
+	var parsed semver
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		parsed.prerelease = version[i+1:]
+		version = version[:i]
+	}
+
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		parsed.parts = append(parsed.parts, n)
+	}
+	return parsed, true
+}
+
+// compare returns -1, 0 or 1. A prerelease sorts before the release it precedes.
+func (v semver) compare(other semver) int {
+	for i := 0; i < len(v.parts) || i < len(other.parts); i++ {
+		a, b := component(v.parts, i), component(other.parts, i)
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.prerelease == other.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case other.prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.prerelease, other.prerelease)
+}
+
+func component(parts []int, i int) int {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return 0
+}
+
+// comparePrerelease compares dot-separated prerelease identifiers: numeric
+// identifiers compare numerically and sort before alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		x, xErr := strconv.Atoi(left[i])
+		y, yErr := strconv.Atoi(right[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				if x < y {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(left[i], right[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(left) < len(right):
+		return -1
+	case len(left) > len(right):
+		return 1
+	}
+	return 0
+}
+
+// compareVersions orders two version strings semantically. Versions that do not
+// parse sort before all others and are compared lexically among themselves.
+func compareVersions(a, b string) int {
+	left, leftOK := parseSemver(a)
+	right, rightOK := parseSemver(b)
+
+	switch {
+	case leftOK && rightOK:
+		if c := left.compare(right); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case leftOK:
+		return 1
+	case rightOK:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// sortVersions sorts versions in ascending semantic order.
+func sortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+}
+
+// isPrerelease reports whether the version carries a prerelease or snapshot qualifier.
+func isPrerelease(version string) bool {
+	parsed, ok := parseSemver(version)
+	return !ok || parsed.prerelease != ""
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"3.57.9", "3.57.9", 0},
+		{"3.57.10", "3.57.9", 1},
+		{"3.9.0", "3.10.0", -1},
+		{"4", "3.99.99", 1},
+		{"3.57", "3.57.0", -1},
+		{"3.58.0-rc.1", "3.58.0", -1},
+		{"3.58.0-rc.2", "3.58.0-rc.10", -1},
+		{"3.58.0-SNAPSHOT", "3.57.9", 1},
+		{"3.58.0-1", "3.58.0-alpha", -1},
+		{"v1.2.3", "1.2.2", 1},
+		{"not-a-version", "0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compareVersions(tt.a, tt.b))
+			assert.Equal(t, -tt.expected, compareVersions(tt.b, tt.a))
+		})
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := []string{"3.10.0", "3.9.1", "3.10.0-rc.1", "2.0.0", "3.9.10"}
+	sortVersions(versions)
+	assert.Equal(t, []string{"2.0.0", "3.9.1", "3.9.10", "3.10.0-rc.1", "3.10.0"}, versions)
+}
+
+func TestIsPrerelease(t *testing.T) {
+	assert.False(t, isPrerelease("3.57.9"))
+	assert.True(t, isPrerelease("3.58.0-rc.1"))
+	assert.True(t, isPrerelease("3.58.0-SNAPSHOT"))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// servePasswordEnv supplies the serve password without exposing it in the process list.
+const servePasswordEnv = "MODERNE_SERVE_PASSWORD"
+
+// Server exposes a local Maven-layout directory, such as one written by sync,
+// as a repository the installer can use as its baseUrl.
+type Server struct {
+	dir      string
+	username string
+	password string
+	logger   *Logger
+}
+
+// runServe implements the serve subcommand.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	dir := flags.String("dir", "", "Maven-layout directory to serve (required)")
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	username := flags.String("username", "", "Require basic auth with this user name")
+	password := flags.String("password", "", "Basic auth password (default: $"+servePasswordEnv+")")
+	certFile := flags.String("tls-cert", "", "Serve HTTPS with this PEM certificate")
+	keyFile := flags.String("tls-key", "", "Private key for -tls-cert")
+	flags.Parse(args)
+
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+	if info, err := os.Stat(*dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", *dir)
+	}
+	if (*certFile == "") != (*keyFile == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+	if *password == "" {
+		*password = os.Getenv(servePasswordEnv)
+	}
+	if (*username == "") != (*password == "") {
+		return fmt.Errorf("basic auth requires both -username and a password")
+	}
+
+	server := &Server{dir: *dir, username: *username, password: *password, logger: NewLogger()}
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	scheme := "http"
+	if *certFile != "" {
+		scheme = "https"
+	}
+	server.logger.Step("Serving %s", *dir)
+	server.logger.Info("Listening on %s://%s", scheme, *addr)
+	if server.username != "" {
+		server.logger.Info("Basic auth required for user %s", server.username)
+	}
+
+	var err error
+	if *certFile != "" {
+		err = httpServer.ListenAndServeTLS(*certFile, *keyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Handler returns the HTTP handler serving the directory read-only.
+// Partial downloads and other temporary files are never served.
+func (s *Server) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="moderne-cli"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			s.logger.Warning("%s %s 401", r.Method, r.URL.Path)
+			return
+		}
+
+		name := path.Base(r.URL.Path)
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, partFileSuffix) || strings.HasSuffix(name, validatorFileSuffix) {
+			http.NotFound(w, r)
+			return
+		}
+		if name == metadataFileName {
+			w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		}
+
+		s.logger.Info("%s %s", r.Method, r.URL.Path)
+		files.ServeHTTP(w, r)
+	})
+}
+
+// authorized checks basic auth credentials when a user is configured.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.username == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(s.username))
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(s.password))
+	return userMatch&passwordMatch == 1
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerHandler(t *testing.T) {
+	dir := writeFileRepository(t, "1.0.0", []byte("fake jar content"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.0.0", "moderne-cli-1.0.0.jar.part"), []byte("partial"), 0644))
+
+	t.Run("serves artifacts and metadata", func(t *testing.T) {
+		server := httptest.NewServer((&Server{dir: dir, logger: NewLogger()}).Handler())
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/maven-metadata.xml")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/xml"))
+
+		resp, err = http.Get(server.URL + "/1.0.0/moderne-cli-1.0.0.jar.sha256")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("hides partial downloads", func(t *testing.T) {
+		server := httptest.NewServer((&Server{dir: dir, logger: NewLogger()}).Handler())
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/1.0.0/moderne-cli-1.0.0.jar.part")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		server := httptest.NewServer((&Server{dir: dir, logger: NewLogger()}).Handler())
+		defer server.Close()
+
+		resp, err := http.Post(server.URL+"/maven-metadata.xml", "text/xml", strings.NewReader("x"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("requires basic auth when configured", func(t *testing.T) {
+		server := httptest.NewServer((&Server{dir: dir, username: "user", password: "secret", logger: NewLogger()}).Handler())
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/maven-metadata.xml")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/maven-metadata.xml", nil)
+		req.SetBasicAuth("user", "wrong")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		req.SetBasicAuth("user", "secret")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestServerAsInstallerSource(t *testing.T) {
+	content := []byte("fake jar content")
+	dir := writeFileRepository(t, "1.0.0", content)
+	server := httptest.NewTLSServer((&Server{dir: dir, username: "user", password: "secret", logger: NewLogger()}).Handler())
+	defer server.Close()
+
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	download := DownloadConfig{
+		BaseURL:   server.URL,
+		Auth:      &AuthConfig{Username: "user", Password: "secret"},
+		TLS:       &TLSConfig{CAPem: string(caPem)},
+		Checksum:  ChecksumRequired,
+		Signature: SignatureOff,
+	}
+	repository, err := NewRepository(&download, NewLogger())
+	require.NoError(t, err)
+
+	latest, err := repository.LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", latest)
+
+	binDir := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	installer := &Installer{
+		version:     "1.0.0",
+		config:      &Config{Download: download},
+		binDir:      binDir,
+		jarPath:     filepath.Join(binDir, "moderne-cli-1.0.0.jar"),
+		jarFileName: "moderne-cli-1.0.0.jar",
+		repository:  repository,
+		logger:      NewLogger(),
+	}
+	require.NoError(t, installer.downloadJAR())
+
+	installed, err := os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, installed)
+}
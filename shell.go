@@ -26,13 +26,13 @@ func (i *Installer) configureUnixAlias() error {
 		return err
 	}
 
-	aliasLine := fmt.Sprintf(`alias %s="java -jar %s"`, aliasName, i.jarPath)
-	markerComment := "# Moderne CLI alias (managed by installer)"
+	// Point at the moderne-cli.jar symlink so `use` can switch versions
+	aliasLine := fmt.Sprintf(`alias %s="java -jar %s"`, aliasName, i.currentJARPath())
 
 	shellConfigs := i.detectUnixShellConfigs(homeDir)
 
 	for _, configFile := range shellConfigs {
-		if err := i.updateShellConfig(configFile, markerComment, aliasLine); err != nil {
+		if err := i.updateShellConfig(configFile, aliasMarker, aliasLine); err != nil {
 			i.logger.Warning("Failed to update %s: %v", configFile, err)
 		} else {
 			i.logger.Success("Updated %s", configFile)
@@ -81,16 +81,16 @@ func (i *Installer) configureWindowsAlias() error {
 }
 
 func (i *Installer) configurePowerShellProfile(homeDir string) error {
-	psProfileDir := filepath.Join(homeDir, "Documents", "WindowsPowerShell")
-	if err := os.MkdirAll(psProfileDir, 0755); err != nil {
+	profilePath := powerShellProfilePath(homeDir)
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
 		return fmt.Errorf("failed to create PowerShell profile directory: %w", err)
 	}
 
-	profilePath := filepath.Join(psProfileDir, "Microsoft.PowerShell_profile.ps1")
-	functionDef := fmt.Sprintf(`function %s { java -jar "%s" $args }`, aliasName, i.jarPath)
-	markerComment := "# Moderne CLI alias (managed by installer)"
+	// Read the active version on each call so `use` can switch versions
+	functionDef := fmt.Sprintf(`function %s { $v = (Get-Content "%s").Trim(); java -jar "%s" $args }`,
+		aliasName, filepath.Join(i.binDir, currentFileName), filepath.Join(i.binDir, jarFilePrefix+"$v"+jarFileSuffix))
 
-	if err := i.updateShellConfig(profilePath, markerComment, functionDef); err != nil {
+	if err := i.updateShellConfig(profilePath, aliasMarker, functionDef); err != nil {
 		return err
 	}
 
@@ -98,9 +98,15 @@ func (i *Installer) configurePowerShellProfile(homeDir string) error {
 	return nil
 }
 
+// powerShellProfilePath returns the Windows PowerShell profile the function is written to.
+func powerShellProfilePath(homeDir string) string {
+	return filepath.Join(homeDir, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1")
+}
+
 func (i *Installer) createBatchFile() error {
-	batchPath := filepath.Join(i.binDir, "mod.bat")
-	batchContent := fmt.Sprintf("@echo off\njava -jar \"%s\" %%*\n", i.jarPath)
+	batchPath := filepath.Join(i.binDir, batchFileName)
+	batchContent := fmt.Sprintf("@echo off\nsetlocal\nset /p MODERNE_CLI_VERSION=<\"%%~dp0%s\"\njava -jar \"%%~dp0%s%%MODERNE_CLI_VERSION%%%s\" %%*\n",
+		currentFileName, jarFilePrefix, jarFileSuffix)
 
 	if err := os.WriteFile(batchPath, []byte(batchContent), 0755); err != nil {
 		return err
@@ -118,7 +124,10 @@ func (i *Installer) updateShellConfig(configFile, marker, content string) error
 	}
 
 	lines := strings.Split(string(existingContent), "\n")
-	newLines := i.removeExistingAlias(lines, marker)
+	newLines, replaced := i.removeExistingAlias(lines, marker)
+	if replaced {
+		i.logger.Info("Replacing existing Moderne CLI alias")
+	}
 
 	// Add new alias
 	newLines = append(newLines, "", marker, content)
@@ -127,14 +136,20 @@ func (i *Installer) updateShellConfig(configFile, marker, content string) error
 	return os.WriteFile(configFile, []byte(newContent), 0644)
 }
 
-func (i *Installer) removeExistingAlias(lines []string, marker string) []string {
+// removeExistingAlias drops the marker line, the line after it, and the blank
+// line updateShellConfig adds before the marker. It reports whether a block was found.
+func (i *Installer) removeExistingAlias(lines []string, marker string) ([]string, bool) {
 	var newLines []string
 	skipNext := false
+	found := false
 
 	for _, line := range lines {
 		if strings.Contains(line, marker) {
 			skipNext = true
-			i.logger.Info("Replacing existing Moderne CLI alias")
+			found = true
+			if n := len(newLines); n > 0 && newLines[n-1] == "" {
+				newLines = newLines[:n-1]
+			}
 			continue
 		}
 		if skipNext {
@@ -144,5 +159,5 @@ func (i *Installer) removeExistingAlias(lines []string, marker string) []string
 		newLines = append(newLines, line)
 	}
 
-	return newLines
+	return newLines, found
 }
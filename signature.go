@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+//go:embed moderne-signing-key.asc
+var embeddedSigningKey embed.FS
+
+const (
+	signingKeyFileName = "moderne-signing-key.asc"
+	armoredKeyHeader   = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+	// moderneSigningKeyFingerprint pins the key embedded from moderne-signing-key.asc.
+	// It is kept here rather than in the key file so replacing the key alone
+	// cannot change which key is trusted.
+	moderneSigningKeyFingerprint = ""
+)
+
+// Signature verification modes for download.signature.
+const (
+	SignatureRequired = "required"
+	SignatureOptional = "optional"
+	SignatureOff      = "off"
+)
+
+// verifySignature checks the detached .asc signature published next to the
+// artifact against the pinned Moderne public key.
+func (i *Installer) verifySignature(client *http.Client, artifactURL, path string) error {
+	mode := i.config.Download.SignatureMode()
+	if mode == SignatureOff {
+		i.logger.Warning("PGP signature verification is disabled")
+		return nil
+	}
+
+	keyring, keySource, err := loadSigningKeys(i.config.Download.SigningKey)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		if mode == SignatureRequired {
+			return fmt.Errorf("PGP signature verification required but no signing key is configured")
+		}
+		i.logger.Warning("No PGP signing key configured, skipping signature verification")
+		return nil
+	}
+
+	signatureURL := artifactURL + ".asc"
+	signature, found, err := fetchSignature(client, signatureURL)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// Every release is signed, so a missing signature only passes with a user-supplied key
+		if mode == SignatureRequired || keySource == "embedded" {
+			return fmt.Errorf("no PGP signature found at %s", redactURL(signatureURL))
+		}
+		i.logger.Warning("No PGP signature found, skipping signature verification")
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file for signature verification: %w", err)
+	}
+	defer file.Close()
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, file, bytes.NewReader(signature), nil)
+	if err != nil {
+		return fmt.Errorf("PGP signature verification failed for %s: %w", redactURL(signatureURL), err)
+	}
+
+	i.logger.Success("Verified PGP signature (key %X from %s)", signer.PrimaryKey.KeyId, keySource)
+	return nil
+}
+
+// loadSigningKeys returns the configured keyring, falling back to the embedded key.
+// The override may be an armored key block or a path to a key file.
+// A nil keyring means no key is available.
+func loadSigningKeys(override string) (openpgp.EntityList, string, error) {
+	var data []byte
+	var source string
+
+	switch {
+	case strings.Contains(override, armoredKeyHeader):
+		data, source = []byte(override), "config"
+	case override != "":
+		content, err := os.ReadFile(override)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read signing key: %w", err)
+		}
+		data, source = content, override
+	default:
+		content, err := embeddedSigningKey.ReadFile(signingKeyFileName)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read embedded signing key: %w", err)
+		}
+		data, source = content, "embedded"
+	}
+
+	// Strip any commentary around the armored block
+	start := bytes.Index(data, []byte(armoredKeyHeader))
+	if start < 0 {
+		if override != "" {
+			return nil, "", fmt.Errorf("signing key from %s is not an armored PGP public key", source)
+		}
+		return nil, "", nil
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data[start:]))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse signing key from %s: %w", source, err)
+	}
+
+	if override != "" {
+		return keyring, source, nil
+	}
+
+	// Only the pinned key is trusted from the embedded file
+	for _, entity := range keyring {
+		if fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint) == moderneSigningKeyFingerprint {
+			return openpgp.EntityList{entity}, source, nil
+		}
+	}
+	return nil, "", fmt.Errorf("embedded signing key does not match pinned fingerprint %q", moderneSigningKeyFingerprint)
+}
+
+// fetchSignature downloads a detached signature file.
+// A missing file is reported with found == false rather than an error.
+func fetchSignature(client *http.Client, signatureURL string) ([]byte, bool, error) {
+	resp, err := client.Get(signatureURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("failed to fetch signature %s: %s", redactURL(signatureURL), resp.Status)
+	}
+
+	signature, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	return signature, true, nil
+}
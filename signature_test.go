@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSigningKey generates a throwaway key pair and returns it with its armored public key.
+func newTestSigningKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("Test Signer", "", "signer@example.com", nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	return entity, buf.String()
+}
+
+func signContent(t *testing.T, entity *openpgp.Entity, content []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(content), nil))
+	return buf.Bytes()
+}
+
+func TestLoadSigningKeys(t *testing.T) {
+	_, publicKey := newTestSigningKey(t)
+
+	t.Run("loads inline armored key", func(t *testing.T) {
+		keyring, source, err := loadSigningKeys(publicKey)
+		require.NoError(t, err)
+		assert.Len(t, keyring, 1)
+		assert.Equal(t, "config", source)
+	})
+
+	t.Run("loads key from file", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "key.asc")
+		require.NoError(t, os.WriteFile(keyPath, []byte("# comment\n"+publicKey), 0644))
+
+		keyring, source, err := loadSigningKeys(keyPath)
+		require.NoError(t, err)
+		assert.Len(t, keyring, 1)
+		assert.Equal(t, keyPath, source)
+	})
+
+	t.Run("returns error for file without key", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "key.asc")
+		require.NoError(t, os.WriteFile(keyPath, []byte("not a key"), 0644))
+
+		_, _, err := loadSigningKeys(keyPath)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not an armored PGP public key")
+	})
+}
+
+func TestEmbeddedSigningKey(t *testing.T) {
+	keyring, source, err := loadSigningKeys("")
+	require.NoError(t, err)
+	require.Len(t, keyring, 1, "moderne-signing-key.asc must contain the Moderne release key")
+	assert.Equal(t, "embedded", source)
+	assert.Equal(t, moderneSigningKeyFingerprint, fmt.Sprintf("%X", keyring[0].PrimaryKey.Fingerprint))
+}
+
+func TestVerifySignature(t *testing.T) {
+	entity, publicKey := newTestSigningKey(t)
+	content := []byte("fake jar content")
+
+	setup := func(t *testing.T, signature []byte, download DownloadConfig) (*Installer, string, string) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/artifact.jar.asc" && signature != nil {
+				w.Write(signature)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		t.Cleanup(server.Close)
+
+		path := filepath.Join(t.TempDir(), "artifact.jar")
+		require.NoError(t, os.WriteFile(path, content, 0644))
+
+		installer := &Installer{config: &Config{Download: download}, logger: NewLogger()}
+		return installer, server.URL + "/artifact.jar", path
+	}
+
+	t.Run("accepts valid signature", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, signContent(t, entity, content), DownloadConfig{
+			Signature:  SignatureRequired,
+			SigningKey: publicKey,
+		})
+
+		assert.NoError(t, installer.verifySignature(http.DefaultClient, artifactURL, path))
+	})
+
+	t.Run("rejects signature over different content", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, signContent(t, entity, []byte("other")), DownloadConfig{
+			SigningKey: publicKey,
+		})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PGP signature verification failed")
+	})
+
+	t.Run("rejects bad signature when optional", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, signContent(t, entity, []byte("other")), DownloadConfig{
+			Signature:  SignatureOptional,
+			SigningKey: publicKey,
+		})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PGP signature verification failed")
+	})
+
+	t.Run("ignores bad signature when off", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, signContent(t, entity, []byte("other")), DownloadConfig{
+			Signature:  SignatureOff,
+			SigningKey: publicKey,
+		})
+
+		assert.NoError(t, installer.verifySignature(http.DefaultClient, artifactURL, path))
+	})
+
+	t.Run("rejects signature from unknown key", func(t *testing.T) {
+		other, _ := newTestSigningKey(t)
+		installer, artifactURL, path := setup(t, signContent(t, other, content), DownloadConfig{
+			SigningKey: publicKey,
+		})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PGP signature verification failed")
+	})
+
+	t.Run("requires signature file when required", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, nil, DownloadConfig{
+			Signature:  SignatureRequired,
+			SigningKey: publicKey,
+		})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no PGP signature found")
+	})
+
+	t.Run("skips missing signature when optional", func(t *testing.T) {
+		installer, artifactURL, path := setup(t, nil, DownloadConfig{SigningKey: publicKey})
+
+		assert.NoError(t, installer.verifySignature(http.DefaultClient, artifactURL, path))
+	})
+
+	// The embedded key is covered by TestEmbeddedSigningKey; these need it present
+	skipWithoutEmbeddedKey := func(t *testing.T) {
+		if keyring, _, _ := loadSigningKeys(""); keyring == nil {
+			t.Skip("no embedded signing key")
+		}
+	}
+
+	t.Run("requires signature for the embedded key by default", func(t *testing.T) {
+		skipWithoutEmbeddedKey(t)
+		installer, artifactURL, path := setup(t, nil, DownloadConfig{})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no PGP signature found")
+	})
+
+	t.Run("rejects signature from another key than the embedded one", func(t *testing.T) {
+		skipWithoutEmbeddedKey(t)
+		installer, artifactURL, path := setup(t, signContent(t, entity, content), DownloadConfig{})
+
+		err := installer.verifySignature(http.DefaultClient, artifactURL, path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PGP signature verification failed")
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	snapshotSuffix     = "-SNAPSHOT"
+	snapshotFileSuffix = ".snapshot"
+)
+
+// resolveSnapshotVersion returns the timestamped version of the newest build of
+// a -SNAPSHOT version, e.g. 3.58.0-20240101.120000-5 for 3.58.0-SNAPSHOT. It
+// returns the version unchanged when the repository has no version-level
+// metadata, as with a plain HTTP server or a directory written by sync.
+func resolveSnapshotVersion(m *mirror, version string) (string, error) {
+	metadata, err := FetchMetadata(m.baseURL+"/"+version, m.client)
+	var status *metadataStatusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return version, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("snapshot %s: %w", version, err)
+	}
+
+	unique, ok := snapshotJARVersion(metadata, version)
+	if !ok {
+		return "", fmt.Errorf("snapshot %s: no build found in metadata", version)
+	}
+	return unique, nil
+}
+
+// snapshotJARVersion reads the timestamped JAR version from version-level metadata.
+// The <snapshotVersions> entry for the JAR wins; older repositories only publish
+// <snapshot>, from which the version is derived.
+func snapshotJARVersion(metadata *MavenMetadata, version string) (string, bool) {
+	for _, snapshotVersion := range metadata.Versioning.SnapshotVersions {
+		if snapshotVersion.Extension == "jar" && snapshotVersion.Classifier == "" && snapshotVersion.Value != "" {
+			return snapshotVersion.Value, true
+		}
+	}
+
+	snapshot := metadata.Versioning.Snapshot
+	if snapshot == nil || snapshot.Timestamp == "" || snapshot.BuildNumber == 0 {
+		return "", false
+	}
+	base := strings.TrimSuffix(version, snapshotSuffix)
+	return fmt.Sprintf("%s-%s-%d", base, snapshot.Timestamp, snapshot.BuildNumber), true
+}
+
+// installedSnapshot returns the timestamped version recorded when a snapshot
+// JAR was installed, or "" if none is recorded.
+func installedSnapshot(jarPath string) string {
+	content, err := os.ReadFile(jarPath + snapshotFileSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// recordSnapshot remembers which timestamped build a snapshot JAR came from.
+func recordSnapshot(jarPath, unique string) error {
+	return writeFileAtomic(jarPath+snapshotFileSuffix, []byte(unique+"\n"))
+}
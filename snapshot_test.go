@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snapshotMetadataTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata modelVersion="1.1.0">
+  <groupId>io.moderne</groupId>
+  <artifactId>moderne-cli</artifactId>
+  <version>3.58.0-SNAPSHOT</version>
+  <versioning>
+    <snapshot>
+      <timestamp>20240101.120000</timestamp>
+      <buildNumber>%[1]d</buildNumber>
+    </snapshot>
+    <lastUpdated>20240101120000</lastUpdated>
+    <snapshotVersions>
+      <snapshotVersion>
+        <classifier>sources</classifier>
+        <extension>jar</extension>
+        <value>3.58.0-20240101.120000-%[1]d</value>
+      </snapshotVersion>
+      <snapshotVersion>
+        <extension>jar</extension>
+        <value>3.58.0-20240101.120000-%[1]d</value>
+      </snapshotVersion>
+      <snapshotVersion>
+        <extension>pom</extension>
+        <value>3.58.0-20240101.120000-%[1]d</value>
+      </snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`
+
+func TestSnapshotJARVersion(t *testing.T) {
+	t.Run("uses snapshotVersions entry for the JAR", func(t *testing.T) {
+		metadata := &MavenMetadata{Versioning: Versioning{
+			Snapshot: &Snapshot{Timestamp: "20240101.120000", BuildNumber: 3},
+			SnapshotVersions: []SnapshotVersion{
+				{Classifier: "sources", Extension: "jar", Value: "wrong"},
+				{Extension: "jar", Value: "3.58.0-20240102.130000-4"},
+			},
+		}}
+
+		version, ok := snapshotJARVersion(metadata, "3.58.0-SNAPSHOT")
+		assert.True(t, ok)
+		assert.Equal(t, "3.58.0-20240102.130000-4", version)
+	})
+
+	t.Run("derives version from snapshot element", func(t *testing.T) {
+		metadata := &MavenMetadata{Versioning: Versioning{
+			Snapshot: &Snapshot{Timestamp: "20240101.120000", BuildNumber: 3},
+		}}
+
+		version, ok := snapshotJARVersion(metadata, "3.58.0-SNAPSHOT")
+		assert.True(t, ok)
+		assert.Equal(t, "3.58.0-20240101.120000-3", version)
+	})
+
+	t.Run("fails without build information", func(t *testing.T) {
+		_, ok := snapshotJARVersion(&MavenMetadata{}, "3.58.0-SNAPSHOT")
+		assert.False(t, ok)
+	})
+}
+
+func TestDownloadSnapshot(t *testing.T) {
+	buildNumber := 1
+	var jarRequests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unique := fmt.Sprintf("3.58.0-20240101.120000-%d", buildNumber)
+		switch r.URL.Path {
+		case "/3.58.0-SNAPSHOT/maven-metadata.xml":
+			fmt.Fprintf(w, snapshotMetadataTemplate, buildNumber)
+		case "/3.58.0-SNAPSHOT/moderne-cli-" + unique + ".jar":
+			jarRequests = append(jarRequests, r.URL.Path)
+			w.Write([]byte("build " + unique))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	binDir := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	newInstaller := func() *Installer {
+		return &Installer{
+			version:     "3.58.0-SNAPSHOT",
+			config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
+			binDir:      binDir,
+			jarPath:     filepath.Join(binDir, "moderne-cli-3.58.0-SNAPSHOT.jar"),
+			jarFileName: "moderne-cli-3.58.0-SNAPSHOT.jar",
+			logger:      NewLogger(),
+		}
+	}
+
+	installer := newInstaller()
+	require.NoError(t, installer.downloadJAR())
+	content, err := os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, "build 3.58.0-20240101.120000-1", string(content))
+	assert.Equal(t, "3.58.0-20240101.120000-1", installedSnapshot(installer.jarPath))
+
+	// Same build: nothing is downloaded
+	require.NoError(t, newInstaller().downloadJAR())
+	assert.Len(t, jarRequests, 1)
+
+	// Newer build: the JAR is replaced
+	buildNumber = 2
+	require.NoError(t, newInstaller().downloadJAR())
+	assert.Len(t, jarRequests, 2)
+	content, err = os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, "build 3.58.0-20240101.120000-2", string(content))
+	assert.Equal(t, "3.58.0-20240101.120000-2", installedSnapshot(installer.jarPath))
+}
+
+func TestDownloadSnapshotWithoutVersionMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/3.58.0-SNAPSHOT/moderne-cli-3.58.0-SNAPSHOT.jar" {
+			w.Write([]byte("plain snapshot"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	binDir := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	installer := &Installer{
+		version:     "3.58.0-SNAPSHOT",
+		config:      &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: server.URL}},
+		binDir:      binDir,
+		jarPath:     filepath.Join(binDir, "moderne-cli-3.58.0-SNAPSHOT.jar"),
+		jarFileName: "moderne-cli-3.58.0-SNAPSHOT.jar",
+		logger:      NewLogger(),
+	}
+
+	require.NoError(t, installer.downloadJAR())
+	content, err := os.ReadFile(installer.jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, "plain snapshot", string(content))
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	metadataFileName     = "maven-metadata.xml"
+	defaultGroupID       = "io.moderne"
+	defaultArtifactID    = "moderne-cli"
+	metadataTimestampFmt = "20060102150405"
+)
+
+// sidecarExtensions are the files published next to a JAR that sync mirrors with it.
+var sidecarExtensions = []string{"sha512", "sha256", "sha1", "md5", "asc"}
+
+// versionSelection chooses which versions the sync command mirrors. The
+// newest versions are picked from the release channel only.
+type versionSelection struct {
+	latest   int
+	versions []string
+	from, to string
+	channel  string
+}
+
+// Syncer mirrors CLI versions from the configured repository into a local
+// directory with the Maven layout that downloadJAR expects.
+type Syncer struct {
+	config     *Config
+	repository *Repository
+	dest       string
+	logger     *Logger
+}
+
+// runSync implements the sync subcommand.
+func runSync(config *Config, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	dest := flags.String("dest", "", "Directory to mirror into (required)")
+	latest := flags.Int("latest", 0, "Mirror the newest N versions (default: 1 when nothing else is selected)")
+	versions := flags.String("versions", "", "Comma-separated list of versions to mirror")
+	versionRange := flags.String("range", "", "Inclusive version range to mirror, e.g. 3.50.0..3.57.9")
+	flags.Parse(args)
+
+	if *dest == "" {
+		return fmt.Errorf("-dest is required")
+	}
+
+	selection := versionSelection{latest: *latest, channel: config.Download.ChannelMode()}
+	for _, version := range strings.Split(*versions, ",") {
+		if version = strings.TrimSpace(version); version != "" {
+			selection.versions = append(selection.versions, version)
+		}
+	}
+	if *versionRange != "" {
+		from, to, ok := strings.Cut(*versionRange, "..")
+		if !ok {
+			return fmt.Errorf("invalid range %q, expected FROM..TO", *versionRange)
+		}
+		selection.from, selection.to = strings.TrimSpace(from), strings.TrimSpace(to)
+	}
+
+	logger := NewLogger()
+	repository, err := NewRepository(&config.Download, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	syncer := &Syncer{config: config, repository: repository, dest: *dest, logger: logger}
+	return syncer.Run(selection)
+}
+
+// Run mirrors the selected versions and regenerates maven-metadata.xml.
+// A failed version does not stop the others; all failures are reported at the end.
+func (s *Syncer) Run(selection versionSelection) error {
+	s.logger.Step("Syncing Moderne CLI versions")
+	s.logger.Info("Source: %s", s.repository.Describe())
+	s.logger.Info("Destination: %s", s.dest)
+
+	metadata, err := s.repository.Metadata()
+	if err != nil {
+		return err
+	}
+
+	versions, err := selection.apply(availableVersions(metadata))
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions match the selection")
+	}
+	s.logger.Info("Versions: %s", strings.Join(versions, ", "))
+
+	var errs []error
+	for _, version := range versions {
+		if err := s.syncVersion(version); err != nil {
+			s.logger.Warning("Failed to sync %s: %v", version, err)
+			errs = append(errs, fmt.Errorf("%s: %w", version, err))
+		}
+	}
+
+	if err := s.writeMetadata(metadata); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// apply returns the selected versions in ascending order. Explicit versions are
+// taken as given; the newest version in the channel is selected when nothing
+// else is.
+func (sel versionSelection) apply(available []string) ([]string, error) {
+	selected := map[string]bool{}
+	for _, version := range sel.versions {
+		selected[version] = true
+	}
+
+	if sel.from != "" || sel.to != "" {
+		for _, bound := range []string{sel.from, sel.to} {
+			if _, ok := parseSemver(bound); bound != "" && !ok {
+				return nil, fmt.Errorf("invalid version in range: %s", bound)
+			}
+		}
+		for _, version := range available {
+			if (sel.from == "" || compareVersions(version, sel.from) >= 0) &&
+				(sel.to == "" || compareVersions(version, sel.to) <= 0) {
+				selected[version] = true
+			}
+		}
+	}
+
+	latest := sel.latest
+	if latest == 0 && len(selected) == 0 && sel.from == "" && sel.to == "" {
+		latest = 1
+	}
+	var inChannel []string
+	for _, version := range available {
+		if channelAllows(sel.channel, version) {
+			inChannel = append(inChannel, version)
+		}
+	}
+	for i := len(inChannel) - 1; i >= 0 && i >= len(inChannel)-latest; i-- {
+		selected[inChannel[i]] = true
+	}
+
+	var versions []string
+	for version := range selected {
+		versions = append(versions, version)
+	}
+	sortVersions(versions)
+	return versions, nil
+}
+
+// syncVersion downloads and verifies one version, then copies its sidecar files
+// from the same mirror. Versions already present in the destination are skipped,
+// except snapshots, which are refreshed when a newer build exists.
+func (s *Syncer) syncVersion(version string) error {
+	s.logger.Step("Syncing %s", version)
+
+	dir := filepath.Join(s.dest, version)
+	jarFileName := jarFilePrefix + version + jarFileSuffix
+	jarPath := filepath.Join(dir, jarFileName)
+	if _, err := os.Stat(jarPath); err == nil && !isSnapshot(version) {
+		s.logger.Info("Already present, skipping")
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	installer := &Installer{
+		version:     version,
+		config:      s.config,
+		binDir:      dir,
+		jarPath:     jarPath,
+		jarFileName: jarFileName,
+		repository:  s.repository,
+		logger:      s.logger,
+	}
+	if err := s.repository.try(installer.downloadFromMirror); err != nil {
+		return err
+	}
+
+	// Snapshot sidecars are published under the timestamped file name
+	m := s.repository.Active()
+	sourceFileName := jarFileName
+	if unique := installedSnapshot(jarPath); unique != "" {
+		sourceFileName = jarFilePrefix + unique + jarFileSuffix
+	}
+	artifactURL := fmt.Sprintf("%s/%s/%s", m.baseURL, version, sourceFileName)
+	for _, extension := range sidecarExtensions {
+		copied, err := copySidecar(m.client, artifactURL+"."+extension, jarPath+"."+extension)
+		if err != nil {
+			return err
+		}
+		if copied {
+			s.logger.Info("Copied %s", jarFileName+"."+extension)
+		}
+	}
+
+	return nil
+}
+
+// copySidecar downloads a small file next to an artifact. It reports false when
+// the repository does not publish it.
+func copySidecar(client *http.Client, sourceURL, path string) (bool, error) {
+	resp, err := client.Get(sourceURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", redactURL(sourceURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to fetch %s: %s", redactURL(sourceURL), resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", redactURL(sourceURL), err)
+	}
+	return true, writeFileAtomic(path, body)
+}
+
+// writeMetadata regenerates maven-metadata.xml from the versions present in the
+// destination, so FetchLatestVersion resolves against the mirror's contents.
+func (s *Syncer) writeMetadata(source *MavenMetadata) error {
+	versions, err := localVersions(s.dest)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", s.dest, err)
+	}
+
+	metadata := buildMetadata(source, versions, time.Now())
+	content, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	content = append([]byte(xml.Header), append(content, '\n')...)
+
+	path := filepath.Join(s.dest, metadataFileName)
+	if err := writeFileAtomic(path, content); err != nil {
+		return err
+	}
+	for _, algorithm := range checksumAlgorithms {
+		h := algorithm.newHash()
+		h.Write(content)
+		if err := writeFileAtomic(path+"."+algorithm.name, []byte(hex.EncodeToString(h.Sum(nil)))); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Success("Wrote %s with %d versions", path, len(versions))
+	return nil
+}
+
+// buildMetadata creates Maven metadata listing versions. <latest> is the newest
+// version and <release> the newest one without a prerelease qualifier.
+func buildMetadata(source *MavenMetadata, versions []string, now time.Time) *MavenMetadata {
+	metadata := &MavenMetadata{
+		GroupID:    defaultGroupID,
+		ArtifactID: defaultArtifactID,
+		Versioning: Versioning{
+			Versions:    versions,
+			LastUpdated: now.UTC().Format(metadataTimestampFmt),
+		},
+	}
+	if source != nil && source.GroupID != "" {
+		metadata.GroupID = source.GroupID
+	}
+	if source != nil && source.ArtifactID != "" {
+		metadata.ArtifactID = source.ArtifactID
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if metadata.Versioning.Latest == "" {
+			metadata.Versioning.Latest = versions[i]
+		}
+		if !isPrerelease(versions[i]) {
+			metadata.Versioning.Release = versions[i]
+			break
+		}
+	}
+	return metadata
+}
+
+// localVersions lists the version directories in dir that contain the CLI JAR.
+func localVersions(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		jarPath := filepath.Join(dir, entry.Name(), jarFilePrefix+entry.Name()+jarFileSuffix)
+		if _, err := os.Stat(jarPath); err == nil {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
+
+// writeFileAtomic writes content to a temporary file and renames it into place.
+func writeFileAtomic(path string, content []byte) error {
+	tmp := path + partFileSuffix
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const syncMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>io.moderne</groupId>
+  <artifactId>moderne-cli</artifactId>
+  <versioning>
+    <latest>3.58.0-rc.1</latest>
+    <release>3.57.9</release>
+    <versions>
+      <version>3.56.0</version>
+      <version>3.57.10</version>
+      <version>3.57.9</version>
+      <version>3.58.0-rc.1</version>
+    </versions>
+    <lastUpdated>20240101000000</lastUpdated>
+  </versioning>
+</metadata>`
+
+// newSyncSource serves syncMetadata and a JAR with a sha256 sidecar for every version.
+func newSyncSource(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/maven-metadata.xml" {
+			w.Write([]byte(syncMetadata))
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if len(parts) != 2 || !strings.Contains(syncMetadata, "<version>"+parts[0]+"</version>") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		content := []byte("jar " + parts[0])
+		switch parts[1] {
+		case "moderne-cli-" + parts[0] + ".jar":
+			w.Write(content)
+		case "moderne-cli-" + parts[0] + ".jar.sha256":
+			sum := sha256.Sum256(content)
+			w.Write([]byte(hex.EncodeToString(sum[:])))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestSyncer(t *testing.T, baseURL string) *Syncer {
+	config := &Config{Download: DownloadConfig{Signature: SignatureOff, BaseURL: baseURL, Retry: &RetryConfig{Attempts: 1}}}
+	repository, err := NewRepository(&config.Download, NewLogger())
+	require.NoError(t, err)
+	return &Syncer{config: config, repository: repository, dest: t.TempDir(), logger: NewLogger()}
+}
+
+func TestVersionSelection(t *testing.T) {
+	available := []string{"3.56.0", "3.57.9", "3.57.10", "3.58.0-rc.1"}
+
+	tests := []struct {
+		name      string
+		selection versionSelection
+		expected  []string
+	}{
+		{"defaults to newest stable", versionSelection{channel: ChannelStable}, []string{"3.57.10"}},
+		{"defaults to newest in channel", versionSelection{channel: ChannelPrerelease}, []string{"3.58.0-rc.1"}},
+		{"latest N", versionSelection{latest: 2, channel: ChannelStable}, []string{"3.57.9", "3.57.10"}},
+		{"latest N in channel", versionSelection{latest: 2, channel: ChannelPrerelease}, []string{"3.57.10", "3.58.0-rc.1"}},
+		{"range", versionSelection{from: "3.57.0", to: "3.57.99"}, []string{"3.57.9", "3.57.10"}},
+		{"open range", versionSelection{from: "3.57.10"}, []string{"3.57.10", "3.58.0-rc.1"}},
+		{"explicit list", versionSelection{versions: []string{"3.56.0", "3.55.0"}}, []string{"3.55.0", "3.56.0"}},
+		{"combined", versionSelection{latest: 1, versions: []string{"3.56.0"}, channel: ChannelStable}, []string{"3.56.0", "3.57.10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := tt.selection.apply(available)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, versions)
+		})
+	}
+
+	t.Run("rejects invalid range bounds", func(t *testing.T) {
+		_, err := versionSelection{from: "abc"}.apply(available)
+		assert.Error(t, err)
+	})
+}
+
+func TestSyncer(t *testing.T) {
+	t.Run("mirrors versions with sidecars and regenerates metadata", func(t *testing.T) {
+		server := newSyncSource(t)
+		syncer := newTestSyncer(t, server.URL)
+
+		require.NoError(t, syncer.Run(versionSelection{from: "3.57.0", to: "3.57.99"}))
+
+		for _, version := range []string{"3.57.9", "3.57.10"} {
+			jarPath := filepath.Join(syncer.dest, version, "moderne-cli-"+version+".jar")
+			content, err := os.ReadFile(jarPath)
+			require.NoError(t, err)
+			assert.Equal(t, "jar "+version, string(content))
+			assert.FileExists(t, jarPath+".sha256")
+			assert.NoFileExists(t, jarPath+".md5")
+		}
+		assert.NoDirExists(t, filepath.Join(syncer.dest, "3.56.0"))
+		assert.FileExists(t, filepath.Join(syncer.dest, "maven-metadata.xml.sha1"))
+
+		// The mirror is usable as a download source
+		latest, err := FetchLatestVersion(mustFileURL(t, syncer.dest), &http.Client{Transport: &fileTransport{base: http.DefaultTransport}})
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", latest)
+	})
+
+	t.Run("keeps previously synced versions in metadata", func(t *testing.T) {
+		server := newSyncSource(t)
+		syncer := newTestSyncer(t, server.URL)
+
+		require.NoError(t, syncer.Run(versionSelection{versions: []string{"3.56.0"}}))
+		require.NoError(t, syncer.Run(versionSelection{channel: ChannelStable}))
+
+		metadata := readMetadata(t, syncer.dest)
+		assert.Equal(t, []string{"3.56.0", "3.57.10"}, metadata.Versioning.Versions)
+		assert.Equal(t, "3.57.10", metadata.Versioning.Latest)
+		assert.Equal(t, "3.57.10", metadata.Versioning.Release)
+	})
+
+	t.Run("reports versions that fail and still writes metadata", func(t *testing.T) {
+		server := newSyncSource(t)
+		syncer := newTestSyncer(t, server.URL)
+
+		err := syncer.Run(versionSelection{versions: []string{"3.56.0", "9.9.9"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "9.9.9")
+
+		metadata := readMetadata(t, syncer.dest)
+		assert.Equal(t, []string{"3.56.0"}, metadata.Versioning.Versions)
+	})
+}
+
+func TestBuildMetadata(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	metadata := buildMetadata(&MavenMetadata{GroupID: "com.example"}, []string{"1.0.0", "1.1.0-rc.1"}, now)
+
+	assert.Equal(t, "com.example", metadata.GroupID)
+	assert.Equal(t, "moderne-cli", metadata.ArtifactID)
+	assert.Equal(t, "1.1.0-rc.1", metadata.Versioning.Latest)
+	assert.Equal(t, "1.0.0", metadata.Versioning.Release)
+	assert.Equal(t, "20240506070809", metadata.Versioning.LastUpdated)
+}
+
+func readMetadata(t *testing.T, dir string) *MavenMetadata {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, "maven-metadata.xml"))
+	require.NoError(t, err)
+
+	var metadata MavenMetadata
+	require.NoError(t, xml.Unmarshal(content, &metadata))
+	return &metadata
+}
+
+func mustFileURL(t *testing.T, path string) string {
+	t.Helper()
+	u, err := fileURL(path)
+	require.NoError(t, err)
+	return u
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsVersions maps download.tls.minVersion values to crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds the TLS client configuration for repositories and proxies.
+// It returns nil when no TLS settings are configured so the Go defaults apply.
+func newTLSConfig(settings *TLSConfig, logger *Logger) (*tls.Config, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	config := &tls.Config{}
+
+	if settings.CAFile != "" || settings.CAPem != "" {
+		// Extend rather than replace the system roots so public repositories keep working
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if settings.CAFile != "" {
+			pem, err := os.ReadFile(settings.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", settings.CAFile)
+			}
+			logger.Info("Trusting CA certificates from %s", settings.CAFile)
+		}
+
+		if settings.CAPem != "" {
+			if !pool.AppendCertsFromPEM([]byte(settings.CAPem)) {
+				return nil, fmt.Errorf("no certificates found in download.tls.caPem")
+			}
+			logger.Info("Trusting CA certificates from config")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		if settings.CertFile == "" || settings.KeyFile == "" {
+			return nil, fmt.Errorf("both certFile and keyFile are required for client certificates")
+		}
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+		logger.Info("Using client certificate %s", settings.CertFile)
+	}
+
+	if settings.MinVersion != "" {
+		version, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(settings.MinVersion), "tls")]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS minVersion %q (use 1.0, 1.1, 1.2 or 1.3)", settings.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if settings.InsecureSkipVerify {
+		logger.Warning("TLS CERTIFICATE VERIFICATION IS DISABLED (download.tls.insecureSkipVerify)")
+		logger.Warning("Downloads can be intercepted or tampered with. Do not use this outside of testing.")
+		config.InsecureSkipVerify = true
+	}
+
+	return config, nil
+}
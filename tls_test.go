@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCA creates a self-signed CA and returns it with its PEM encoding.
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// writeClientCert issues a client certificate from the CA and writes it and its key to dir.
+func writeClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "installer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("returns nil without settings", func(t *testing.T) {
+		config, err := newTLSConfig(nil, NewLogger())
+		require.NoError(t, err)
+		assert.Nil(t, config)
+	})
+
+	t.Run("parses minimum version", func(t *testing.T) {
+		config, err := newTLSConfig(&TLSConfig{MinVersion: "1.3"}, NewLogger())
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+
+		config, err = newTLSConfig(&TLSConfig{MinVersion: "TLS1.2"}, NewLogger())
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	})
+
+	t.Run("rejects unknown minimum version", func(t *testing.T) {
+		_, err := newTLSConfig(&TLSConfig{MinVersion: "1.4"}, NewLogger())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported TLS minVersion")
+	})
+
+	t.Run("rejects CA file without certificates", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0644))
+
+		_, err := newTLSConfig(&TLSConfig{CAFile: caFile}, NewLogger())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no certificates found")
+	})
+
+	t.Run("requires both certificate and key", func(t *testing.T) {
+		_, err := newTLSConfig(&TLSConfig{CertFile: "client.crt"}, NewLogger())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "both certFile and keyFile are required")
+	})
+}
+
+func TestTLSClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testMetadata))
+	}))
+	defer server.Close()
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	fetch := func(t *testing.T, settings *TLSConfig) error {
+		clearProxyEnv(t)
+		download := &DownloadConfig{BaseURL: server.URL, TLS: settings, Retry: &RetryConfig{Attempts: 1}}
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+		_, err = FetchLatestVersion(server.URL, client)
+		return err
+	}
+
+	t.Run("fails for untrusted certificate", func(t *testing.T) {
+		err := fetch(t, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("trusts inline CA", func(t *testing.T) {
+		assert.NoError(t, fetch(t, &TLSConfig{CAPem: string(serverCA)}))
+	})
+
+	t.Run("trusts CA file", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, serverCA, 0644))
+
+		assert.NoError(t, fetch(t, &TLSConfig{CAFile: caFile}))
+	})
+
+	t.Run("skips verification when insecure", func(t *testing.T) {
+		assert.NoError(t, fetch(t, &TLSConfig{InsecureSkipVerify: true}))
+	})
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	ca, caKey, _ := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testMetadata))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	clearProxyEnv(t)
+	certFile, keyFile := writeClientCert(t, ca, caKey, t.TempDir())
+
+	t.Run("presents client certificate", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: server.URL,
+			TLS:     &TLSConfig{CAPem: string(serverCA), CertFile: certFile, KeyFile: keyFile},
+			Retry:   &RetryConfig{Attempts: 1},
+		}
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = FetchLatestVersion(server.URL, client)
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails without client certificate", func(t *testing.T) {
+		download := &DownloadConfig{
+			BaseURL: server.URL,
+			TLS:     &TLSConfig{CAPem: string(serverCA)},
+			Retry:   &RetryConfig{Attempts: 1},
+		}
+		client, err := newHTTPClient(download, NewLogger())
+		require.NoError(t, err)
+
+		_, err = FetchLatestVersion(server.URL, client)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// uninstallSummary records what an uninstall removed, or would remove in a dry run.
+type uninstallSummary struct {
+	removed  []string
+	switched string
+	// batchFile is set when mod.bat was removed; the user may have put its directory on PATH
+	batchFile bool
+}
+
+// runUninstall implements the uninstall subcommand. Without flags it removes
+// the active version; the alias, launchers and current pointer go once no
+// versions are left.
+func runUninstall(config *Config, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	version := flags.String("version", "", "Remove this version only (default: the active version)")
+	all := flags.Bool("all", false, "Remove every installed version, the alias and the launchers")
+	dryRun := flags.Bool("dry-run", false, "Print what would be removed without removing it")
+	flags.Parse(args)
+
+	if *version != "" && *all {
+		return fmt.Errorf("--version and --all cannot be used together")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	installer := NewInstallerWithConfig(*version, config, nil)
+	summary, err := installer.uninstall(homeDir, *all, *dryRun)
+	if err != nil {
+		return err
+	}
+	summary.print(out, *dryRun)
+	return nil
+}
+
+// uninstall removes the installer's version, or all versions, from binDir.
+// Removing the active version switches to the newest remaining one; removing
+// the last version also removes the alias blocks, mod.bat, the pointer, the
+// metadata cache, and the bin and install directories once they are empty.
+func (i *Installer) uninstall(homeDir string, all, dryRun bool) (*uninstallSummary, error) {
+	installed, err := installedVersions(i.binDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed versions: %w", err)
+	}
+	current, err := readCurrent(i.binDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read active version: %w", err)
+	}
+
+	targets := installed
+	if !all {
+		version := i.version
+		if version == "" {
+			version = current
+		}
+		if version == "" && len(installed) == 1 {
+			version = installed[0]
+		}
+		if version == "" {
+			return nil, fmt.Errorf("no active version; specify --version or --all")
+		}
+		if !slices.Contains(installed, version) {
+			return nil, fmt.Errorf("version %s is not installed", version)
+		}
+		targets = []string{version}
+	}
+
+	summary := &uninstallSummary{}
+	remove := func(path string) error {
+		if _, err := os.Lstat(path); err != nil {
+			return nil
+		}
+		summary.removed = append(summary.removed, path)
+		if dryRun {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+
+	for _, version := range targets {
+		for _, path := range i.withVersion(version).versionFiles() {
+			if err := remove(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	remaining := without(installed, targets)
+	if len(remaining) > 0 {
+		if slices.Contains(targets, current) {
+			// Keep mod working by falling back to the newest version left
+			summary.switched = remaining[len(remaining)-1]
+			if !dryRun {
+				if err := i.withVersion(summary.switched).setCurrent(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return summary, nil
+	}
+
+	for _, name := range []string{currentJARName, currentFileName} {
+		if err := remove(filepath.Join(i.binDir, name)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(i.binDir, batchFileName)); err == nil {
+		summary.batchFile = true
+		if err := remove(filepath.Join(i.binDir, batchFileName)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, configFile := range []string{filepath.Join(homeDir, ".bashrc"), filepath.Join(homeDir, ".zshrc"), powerShellProfilePath(homeDir)} {
+		removed, err := i.removeAliasBlock(configFile, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", configFile, err)
+		}
+		if removed {
+			summary.removed = append(summary.removed, fmt.Sprintf("%s alias in %s", aliasName, configFile))
+		}
+	}
+
+	cacheDir := metadataCacheDir()
+	if _, err := os.Stat(cacheDir); err == nil {
+		summary.removed = append(summary.removed, cacheDir)
+		if !dryRun {
+			if err := os.RemoveAll(cacheDir); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", cacheDir, err)
+			}
+		}
+	}
+
+	// Directories go only when nothing else lives in them, innermost first
+	for _, dir := range []string{filepath.Dir(cacheDir), i.binDir, i.installDir} {
+		if dryRun {
+			if emptyAfterRemoval(dir, summary.removed) {
+				summary.removed = append(summary.removed, dir)
+			}
+		} else if os.Remove(dir) == nil {
+			summary.removed = append(summary.removed, dir)
+		}
+	}
+	return summary, nil
+}
+
+// emptyAfterRemoval reports whether an existing directory holds nothing but
+// entries in removed, so a dry run can tell whether it would be deleted.
+func emptyAfterRemoval(dir string, removed []string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !slices.Contains(removed, filepath.Join(dir, entry.Name())) {
+			return false
+		}
+	}
+	return true
+}
+
+// versionFiles lists the files an install of the version may leave in binDir.
+func (i *Installer) versionFiles() []string {
+	return []string{i.jarPath, i.jarPath + snapshotFileSuffix, i.jarPath + partFileSuffix, i.jarPath + partFileSuffix + validatorFileSuffix}
+}
+
+// removeAliasBlock deletes the managed alias block from a shell config file,
+// reporting whether the file had one.
+func (i *Installer) removeAliasBlock(configFile string, dryRun bool) (bool, error) {
+	content, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	lines, found := i.removeExistingAlias(strings.Split(string(content), "\n"), aliasMarker)
+	if !found || dryRun {
+		return found, nil
+	}
+	return true, os.WriteFile(configFile, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// print writes the summary, one removed item per line.
+func (s *uninstallSummary) print(out io.Writer, dryRun bool) {
+	if len(s.removed) == 0 {
+		fmt.Fprintln(out, "Nothing to uninstall")
+		return
+	}
+
+	heading, switched := "Removed:", "Active version is now"
+	if dryRun {
+		heading, switched = "Would remove:", "Active version would become"
+	}
+	fmt.Fprintln(out, heading)
+	for _, item := range s.removed {
+		fmt.Fprintf(out, "  %s\n", item)
+	}
+	if s.switched != "" {
+		fmt.Fprintf(out, "%s %s\n", switched, s.switched)
+	}
+	if s.batchFile {
+		// The installer never edits PATH itself, so there is nothing to undo there
+		fmt.Fprintln(out, "If you added the bin directory to PATH for CMD, remove it from your environment variables")
+	}
+}
+
+// without returns versions minus the ones in exclude, keeping the order.
+func without(versions, exclude []string) []string {
+	var kept []string
+	for _, version := range versions {
+		if !slices.Contains(exclude, version) {
+			kept = append(kept, version)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUninstallFixture installs the given versions into a temporary home,
+// activating the last one, and returns the home and bin directories.
+func newUninstallFixture(t *testing.T, versions ...string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fixture uses Unix alias configuration")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	binDir := filepath.Join(home, installDirName, binDirName)
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.MkdirAll(metadataCacheDir(), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(metadataCacheDir(), "entry.xml"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), []byte("export EDITOR=vi\n"), 0644))
+
+	for _, version := range versions {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, jarFilePrefix+version+jarFileSuffix), []byte(version), 0644))
+	}
+	require.NoError(t, runUse(DefaultConfig(), versions[len(versions)-1:]))
+	return home, binDir
+}
+
+func TestUninstall(t *testing.T) {
+	t.Run("removes the active version and switches to the newest remaining", func(t *testing.T) {
+		home, binDir := newUninstallFixture(t, "3.57.8", "3.57.10", "3.57.9")
+
+		var out bytes.Buffer
+		require.NoError(t, runUninstall(DefaultConfig(), nil, &out))
+		assert.Equal(t, "Removed:\n  "+filepath.Join(binDir, "moderne-cli-3.57.9.jar")+"\nActive version is now 3.57.10\n", out.String())
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", current)
+
+		bashrc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+		assert.Contains(t, string(bashrc), aliasMarker)
+	})
+
+	t.Run("removes a specific version", func(t *testing.T) {
+		_, binDir := newUninstallFixture(t, "3.57.8", "3.57.9")
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-3.57.8.jar.part"), nil, 0644))
+
+		var out bytes.Buffer
+		require.NoError(t, runUninstall(DefaultConfig(), []string{"--version", "3.57.8"}, &out))
+		assert.NotContains(t, out.String(), "Active version")
+
+		versions, err := installedVersions(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"3.57.9"}, versions)
+		assert.NoFileExists(t, filepath.Join(binDir, "moderne-cli-3.57.8.jar.part"))
+	})
+
+	t.Run("removes everything with --all", func(t *testing.T) {
+		home, binDir := newUninstallFixture(t, "3.57.8", "3.57.9")
+
+		var out bytes.Buffer
+		require.NoError(t, runUninstall(DefaultConfig(), []string{"--all"}, &out))
+		assert.Contains(t, out.String(), "mod alias in "+filepath.Join(home, ".bashrc"))
+
+		bashrc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+		assert.Equal(t, "export EDITOR=vi\n", string(bashrc))
+
+		installDir := filepath.Join(home, installDirName)
+		for _, dir := range []string{metadataCacheDir(), binDir, installDir} {
+			assert.NoDirExists(t, dir)
+			assert.Contains(t, out.String(), "  "+dir+"\n")
+		}
+	})
+
+	t.Run("keeps a non-empty install directory", func(t *testing.T) {
+		home, binDir := newUninstallFixture(t, "3.57.9")
+		installDir := filepath.Join(home, installDirName)
+		require.NoError(t, os.MkdirAll(filepath.Join(installDir, "cli"), 0755))
+
+		var out bytes.Buffer
+		require.NoError(t, runUninstall(DefaultConfig(), []string{"--all"}, &out))
+		assert.NoDirExists(t, binDir)
+		assert.NoDirExists(t, filepath.Join(installDir, cacheDirName))
+		assert.DirExists(t, filepath.Join(installDir, "cli"))
+		assert.NotContains(t, out.String(), "  "+installDir+"\n")
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		home, binDir := newUninstallFixture(t, "3.57.9")
+		before, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, runUninstall(DefaultConfig(), []string{"--dry-run"}, &out))
+		assert.Contains(t, out.String(), "Would remove:\n  "+filepath.Join(binDir, "moderne-cli-3.57.9.jar")+"\n")
+		assert.Contains(t, out.String(), filepath.Join(binDir, currentFileName))
+		assert.Contains(t, out.String(), "  "+metadataCacheDir()+"\n")
+		assert.Contains(t, out.String(), "  "+filepath.Join(home, installDirName)+"\n")
+
+		after, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+		assert.FileExists(t, filepath.Join(binDir, "moderne-cli-3.57.9.jar"))
+		assert.FileExists(t, filepath.Join(binDir, currentFileName))
+		assert.DirExists(t, metadataCacheDir())
+	})
+
+	t.Run("rejects a version that is not installed", func(t *testing.T) {
+		newUninstallFixture(t, "3.57.9")
+
+		err := runUninstall(DefaultConfig(), []string{"--version", "3.57.8"}, &bytes.Buffer{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version 3.57.8 is not installed")
+	})
+
+	t.Run("rejects --version with --all", func(t *testing.T) {
+		err := runUninstall(DefaultConfig(), []string{"--version", "3.57.8", "--all"}, &bytes.Buffer{})
+		assert.Error(t, err)
+	})
+}
+
+func TestRemoveExistingAlias(t *testing.T) {
+	installer := &Installer{logger: NewLogger()}
+
+	lines, found := installer.removeExistingAlias([]string{"export EDITOR=vi", "", "", aliasMarker, `alias mod="java -jar x"`}, aliasMarker)
+	assert.True(t, found)
+	assert.Equal(t, []string{"export EDITOR=vi", ""}, lines)
+
+	_, found = installer.removeExistingAlias([]string{"export EDITOR=vi"}, aliasMarker)
+	assert.False(t, found)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// smokeCheckTimeout bounds how long `java -jar <new> --version` may run.
+const smokeCheckTimeout = 2 * time.Minute
+
+// javaExecutable runs the smoke check; tests point it at a stub.
+var javaExecutable = "java"
+
+// runUpgrade implements the upgrade subcommand: install the newest version in
+// the configured channel next to the active one and switch to it once it works.
+func runUpgrade(config *Config, args []string) error {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	offline := flags.Bool("offline", false, "Use cached repository metadata only")
+	refresh := flags.Bool("refresh", false, "Ignore cached repository metadata")
+	flags.Parse(args)
+
+	if *offline && *refresh {
+		return fmt.Errorf("--offline and --refresh cannot be used together")
+	}
+
+	repository, err := NewRepository(&config.Download, NewLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	repository.cache = newMetadataCache(config, *offline, *refresh, NewLogger())
+
+	installer := NewInstallerWithConfig("", config, repository)
+	previous, err := installer.activeVersion()
+	if err != nil {
+		return err
+	}
+
+	latest, err := repository.LatestVersion()
+	if err != nil {
+		return fmt.Errorf("failed to determine latest version: %w", err)
+	}
+	if compareVersions(latest, previous) <= 0 {
+		installer.logger.Success("Already up to date: %s", previous)
+		return nil
+	}
+
+	installer = installer.withVersion(latest)
+	if err := installer.upgrade(previous); err != nil {
+		return err
+	}
+	installer.logger.Success("Upgraded Moderne CLI from %s to %s", previous, latest)
+	return nil
+}
+
+// activeVersion returns the version mod currently runs. Installs made before
+// the current pointer existed fall back to the newest installed version.
+func (i *Installer) activeVersion() (string, error) {
+	current, err := readCurrent(i.binDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read active version: %w", err)
+	}
+	if current != "" {
+		return current, nil
+	}
+
+	installed, err := installedVersions(i.binDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read installed versions: %w", err)
+	}
+	if len(installed) == 0 {
+		return "", fmt.Errorf("no version installed in %s; run the installer first", i.binDir)
+	}
+	return installed[len(installed)-1], nil
+}
+
+// upgrade installs the installer's version alongside previous and makes it
+// active only after the smoke check and post-install commands pass. On any
+// failure previous stays active and the new install is removed.
+func (i *Installer) upgrade(previous string) (err error) {
+	i.logger.Step("Upgrading Moderne CLI from %s to %s", previous, i.version)
+
+	// A version installed earlier and switched away from with `use` is kept on failure
+	_, statErr := os.Stat(i.jarPath)
+	preinstalled := statErr == nil
+	switched := false
+	defer func() {
+		if err != nil {
+			i.rollback(previous, preinstalled, switched)
+		}
+	}()
+
+	if err := i.createDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	if err := i.downloadJAR(); err != nil {
+		return fmt.Errorf("failed to download JAR: %w", err)
+	}
+
+	if err := i.smokeCheck(); err != nil {
+		return fmt.Errorf("smoke check failed: %w", err)
+	}
+
+	i.strictCommands = true
+	if err := i.runPostInstallCommands(); err != nil {
+		return fmt.Errorf("failed to run post-install commands: %w", err)
+	}
+
+	switched = true
+	if err := i.setCurrent(); err != nil {
+		return fmt.Errorf("failed to activate version: %w", err)
+	}
+
+	if err := i.configureShellAlias(); err != nil {
+		return fmt.Errorf("failed to configure shell alias: %w", err)
+	}
+
+	return nil
+}
+
+// smokeCheck runs `java -jar <jar> --version` to make sure the JAR starts.
+func (i *Installer) smokeCheck() error {
+	i.logger.Step("Checking %s", i.jarFileName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), smokeCheckTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, javaExecutable, "-jar", i.jarPath, "--version").CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+
+	i.logger.Success("%s", strings.TrimSpace(string(output)))
+	return nil
+}
+
+// rollback restores previous as the active version and removes the new install.
+func (i *Installer) rollback(previous string, preinstalled, switched bool) {
+	i.logger.Warning("Upgrade to %s failed, keeping %s active", i.version, previous)
+
+	if switched {
+		if err := i.withVersion(previous).setCurrent(); err != nil {
+			i.logger.Warning("Failed to restore %s: %v", previous, err)
+		}
+	}
+
+	if preinstalled {
+		return
+	}
+	for _, path := range i.versionFiles() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			i.logger.Warning("Failed to remove %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUpgradeFixture installs 0.9.0 as the active version in a temporary home
+// and returns a config pointing at a file repository whose latest is 1.0.0.
+func newUpgradeFixture(t *testing.T, java string, commands string) (*Config, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the java stub is a shell script")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv(artifactCacheEnv, "")
+	binDir := filepath.Join(home, installDirName, binDirName)
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-0.9.0.jar"), []byte("0.9.0"), 0644))
+	require.NoError(t, runUse(DefaultConfig(), []string{"0.9.0"}))
+
+	stub := filepath.Join(home, "java")
+	require.NoError(t, os.WriteFile(stub, []byte("#!/bin/sh\n"+java+"\n"), 0755))
+	original := javaExecutable
+	javaExecutable = stub
+	t.Cleanup(func() { javaExecutable = original })
+
+	// Post-install commands are read from the working directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	workDir := t.TempDir()
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() { os.Chdir(originalDir) })
+	if commands != "" {
+		require.NoError(t, os.WriteFile(commandsFileName, []byte(commands), 0644))
+	}
+
+	baseURL, err := fileURL(writeFileRepository(t, "1.0.0", []byte("fake jar content")))
+	require.NoError(t, err)
+	config := DefaultConfig()
+	config.Download.BaseURL = baseURL
+	config.Download.Signature = SignatureOff
+	return config, binDir
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Run("switches to the new version after the checks pass", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, `echo "Moderne CLI v1.0.0"`, "test -n \"$MOD\"\n")
+
+		require.NoError(t, runUpgrade(config, nil))
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", current)
+		versions, err := installedVersions(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.9.0", "1.0.0"}, versions)
+	})
+
+	t.Run("keeps the previous version when the smoke check fails", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, "echo broken >&2; exit 1", "")
+
+		err := runUpgrade(config, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "smoke check failed")
+		assert.Contains(t, err.Error(), "broken")
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "0.9.0", current)
+		assert.NoFileExists(t, filepath.Join(binDir, "moderne-cli-1.0.0.jar"))
+	})
+
+	t.Run("keeps the previous version when a post-install command fails", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, "echo ok", "exit 3\n")
+
+		err := runUpgrade(config, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "command 'exit 3' failed")
+
+		current, err := readCurrent(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, "0.9.0", current)
+		assert.NoFileExists(t, filepath.Join(binDir, "moderne-cli-1.0.0.jar"))
+		content, err := os.ReadFile(filepath.Join(binDir, currentJARName))
+		require.NoError(t, err)
+		assert.Equal(t, "0.9.0", string(content))
+	})
+
+	t.Run("keeps a version that was already installed", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, "exit 1", "")
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-1.0.0.jar"), []byte("fake jar content"), 0644))
+
+		require.Error(t, runUpgrade(config, nil))
+		assert.FileExists(t, filepath.Join(binDir, "moderne-cli-1.0.0.jar"))
+	})
+
+	t.Run("does nothing when already up to date", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, "exit 1", "")
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "moderne-cli-1.0.0.jar"), []byte("fake jar content"), 0644))
+		require.NoError(t, runUse(config, []string{"1.0.0"}))
+
+		require.NoError(t, runUpgrade(config, nil))
+	})
+
+	t.Run("requires an installed version", func(t *testing.T) {
+		config, binDir := newUpgradeFixture(t, "exit 1", "")
+		require.NoError(t, os.RemoveAll(binDir))
+
+		err := runUpgrade(config, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no version installed")
+	})
+}
@@ -5,22 +5,62 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+)
+
+// Release channels for download.channel.
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+	ChannelSnapshot   = "snapshot"
 )
 
 // MavenMetadata represents the maven-metadata.xml structure.
 type MavenMetadata struct {
 	XMLName    xml.Name   `xml:"metadata"`
+	GroupID    string     `xml:"groupId,omitempty"`
+	ArtifactID string     `xml:"artifactId,omitempty"`
 	Versioning Versioning `xml:"versioning"`
 }
 
 // Versioning contains version information from Maven metadata.
+// Snapshot and SnapshotVersions are only present in the version-level
+// metadata of a -SNAPSHOT version.
 type Versioning struct {
-	Latest  string `xml:"latest"`
-	Release string `xml:"release"`
+	Latest           string            `xml:"latest,omitempty"`
+	Release          string            `xml:"release,omitempty"`
+	Versions         []string          `xml:"versions>version"`
+	LastUpdated      string            `xml:"lastUpdated,omitempty"`
+	Snapshot         *Snapshot         `xml:"snapshot,omitempty"`
+	SnapshotVersions []SnapshotVersion `xml:"snapshotVersions>snapshotVersion,omitempty"`
 }
 
-// FetchLatestVersion fetches the latest version from Maven Central metadata.
-func FetchLatestVersion(baseURL string, client *http.Client) (string, error) {
+// Snapshot identifies the newest timestamped build of a -SNAPSHOT version.
+type Snapshot struct {
+	Timestamp   string `xml:"timestamp"`
+	BuildNumber int    `xml:"buildNumber"`
+}
+
+// SnapshotVersion maps an artifact file to its timestamped version.
+type SnapshotVersion struct {
+	Classifier string `xml:"classifier,omitempty"`
+	Extension  string `xml:"extension"`
+	Value      string `xml:"value"`
+	Updated    string `xml:"updated,omitempty"`
+}
+
+// metadataStatusError reports a maven-metadata.xml request that did not return 200.
+type metadataStatusError struct {
+	status string
+	code   int
+}
+
+func (e *metadataStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch metadata: %s", e.status)
+}
+
+// FetchMetadata fetches and parses maven-metadata.xml from the repository.
+func FetchMetadata(baseURL string, client *http.Client) (*MavenMetadata, error) {
 	metadataURL := fmt.Sprintf("%s/maven-metadata.xml", baseURL)
 
 	if client == nil {
@@ -29,33 +69,108 @@ func FetchLatestVersion(baseURL string, client *http.Client) (string, error) {
 
 	resp, err := client.Get(metadataURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch metadata: %w", err)
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch metadata: %s", resp.Status)
+		return nil, &metadataStatusError{status: resp.Status, code: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read metadata: %w", err)
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
 
+	return parseMetadata(body)
+}
+
+// parseMetadata decodes the content of a maven-metadata.xml file.
+func parseMetadata(body []byte) (*MavenMetadata, error) {
 	var metadata MavenMetadata
 	if err := xml.Unmarshal(body, &metadata); err != nil {
-		return "", fmt.Errorf("failed to parse metadata: %w", err)
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
+	return &metadata, nil
+}
 
-	// Prefer <latest>, fall back to <release>
-	version := metadata.Versioning.Latest
-	if version == "" {
-		version = metadata.Versioning.Release
+// FetchLatestVersion fetches the latest stable version from Maven Central metadata.
+func FetchLatestVersion(baseURL string, client *http.Client) (string, error) {
+	metadata, err := FetchMetadata(baseURL, client)
+	if err != nil {
+		return "", err
+	}
+	return latestVersion(metadata, ChannelStable)
+}
+
+// latestVersion returns the highest version in <versions> that the channel
+// allows. <release> and <latest> are only used when the list is absent, since
+// <latest> may point at a snapshot or release candidate.
+func latestVersion(metadata *MavenMetadata, channel string) (string, error) {
+	if versions := metadata.Versioning.Versions; len(versions) > 0 {
+		best := ""
+		for _, version := range versions {
+			if channelAllows(channel, version) && (best == "" || compareVersions(version, best) > 0) {
+				best = version
+			}
+		}
+		if best == "" {
+			return "", fmt.Errorf("no %s version found in metadata", channel)
+		}
+		return best, nil
+	}
+
+	// Stable prefers <release>; other channels prefer <latest>
+	candidates := []string{metadata.Versioning.Latest, metadata.Versioning.Release}
+	if channel == ChannelStable {
+		candidates = []string{metadata.Versioning.Release, metadata.Versioning.Latest}
+	}
+	found := false
+	for _, version := range candidates {
+		if version == "" {
+			continue
+		}
+		if channelAllows(channel, version) {
+			return version, nil
+		}
+		found = true
 	}
+	if found {
+		return "", fmt.Errorf("no %s version found in metadata", channel)
+	}
+
+	return "", fmt.Errorf("no version found in metadata")
+}
 
-	if version == "" {
-		return "", fmt.Errorf("no version found in metadata")
+// channelAllows reports whether a version belongs to the release channel.
+// Stable excludes any qualifier, prerelease adds qualified versions such as
+// release candidates, and snapshot adds -SNAPSHOT builds.
+func channelAllows(channel, version string) bool {
+	switch {
+	case isSnapshot(version):
+		return channel == ChannelSnapshot
+	case isPrerelease(version):
+		return channel == ChannelPrerelease || channel == ChannelSnapshot
 	}
+	return true
+}
 
-	return version, nil
+// isSnapshot reports whether the version is a Maven -SNAPSHOT build.
+func isSnapshot(version string) bool {
+	return strings.HasSuffix(strings.ToUpper(version), "-SNAPSHOT")
+}
+
+// availableVersions returns the versions listed in the metadata, falling back
+// to <release> and <latest> for metadata without a <versions> element.
+func availableVersions(metadata *MavenMetadata) []string {
+	versions := append([]string(nil), metadata.Versioning.Versions...)
+	if len(versions) == 0 {
+		for _, version := range []string{metadata.Versioning.Release, metadata.Versioning.Latest} {
+			if version != "" && (len(versions) == 0 || versions[0] != version) {
+				versions = append(versions, version)
+			}
+		}
+	}
+	sortVersions(versions)
+	return versions
 }
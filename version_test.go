@@ -10,7 +10,7 @@ import (
 )
 
 func TestFetchLatestVersion(t *testing.T) {
-	t.Run("returns latest version from metadata", func(t *testing.T) {
+	t.Run("prefers release over latest without a versions list", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			assert.Equal(t, "/maven-metadata.xml", r.URL.Path)
 			w.WriteHeader(http.StatusOK)
@@ -28,7 +28,31 @@ func TestFetchLatestVersion(t *testing.T) {
 
 		version, err := FetchLatestVersion(server.URL, nil)
 		require.NoError(t, err)
-		assert.Equal(t, "3.57.9", version)
+		assert.Equal(t, "3.57.8", version)
+	})
+
+	t.Run("returns highest stable version from versions list", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <versioning>
+    <latest>3.58.0-SNAPSHOT</latest>
+    <release>3.57.9</release>
+    <versions>
+      <version>3.57.10</version>
+      <version>3.57.9</version>
+      <version>3.58.0-rc.1</version>
+      <version>3.58.0-SNAPSHOT</version>
+    </versions>
+  </versioning>
+</metadata>`))
+		}))
+		defer server.Close()
+
+		version, err := FetchLatestVersion(server.URL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "3.57.10", version)
 	})
 
 	t.Run("falls back to release when latest is empty", func(t *testing.T) {
@@ -112,3 +136,74 @@ func TestFetchLatestVersion(t *testing.T) {
 		assert.Equal(t, "1.0.0", version)
 	})
 }
+
+func TestFetchMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>io.moderne</groupId>
+  <artifactId>moderne-cli</artifactId>
+  <versioning>
+    <latest>3.57.9</latest>
+    <release>3.57.9</release>
+    <versions>
+      <version>3.57.8</version>
+      <version>3.57.9</version>
+    </versions>
+    <lastUpdated>20240101120000</lastUpdated>
+  </versioning>
+</metadata>`))
+	}))
+	defer server.Close()
+
+	metadata, err := FetchMetadata(server.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "io.moderne", metadata.GroupID)
+	assert.Equal(t, "moderne-cli", metadata.ArtifactID)
+	assert.Equal(t, []string{"3.57.8", "3.57.9"}, metadata.Versioning.Versions)
+	assert.Equal(t, "20240101120000", metadata.Versioning.LastUpdated)
+}
+
+func TestLatestVersion(t *testing.T) {
+	metadata := &MavenMetadata{Versioning: Versioning{
+		Latest:   "3.59.0-SNAPSHOT",
+		Release:  "3.58.0-rc.2",
+		Versions: []string{"3.57.9", "3.57.10", "3.58.0-rc.2", "3.58.0-rc.10", "3.59.0-SNAPSHOT"},
+	}}
+
+	tests := []struct {
+		channel  string
+		expected string
+	}{
+		{ChannelStable, "3.57.10"},
+		{ChannelPrerelease, "3.58.0-rc.10"},
+		{ChannelSnapshot, "3.59.0-SNAPSHOT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			version, err := latestVersion(metadata, tt.channel)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+
+	t.Run("falls back to latest for other channels without a versions list", func(t *testing.T) {
+		version, err := latestVersion(&MavenMetadata{Versioning: Versioning{Latest: "2.0.0-rc.1", Release: "1.0.0"}}, ChannelPrerelease)
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0-rc.1", version)
+	})
+
+	t.Run("skips fallback versions outside the channel", func(t *testing.T) {
+		_, err := latestVersion(&MavenMetadata{Versioning: Versioning{Latest: "3.58.0-SNAPSHOT"}}, ChannelStable)
+		assert.ErrorContains(t, err, "no stable version found")
+
+		version, err := latestVersion(&MavenMetadata{Versioning: Versioning{Latest: "3.58.0-SNAPSHOT", Release: "3.58.0-rc.1"}}, ChannelPrerelease)
+		require.NoError(t, err)
+		assert.Equal(t, "3.58.0-rc.1", version)
+	})
+
+	t.Run("returns error when no version in the channel", func(t *testing.T) {
+		_, err := latestVersion(&MavenMetadata{Versioning: Versioning{Versions: []string{"1.0.0-SNAPSHOT"}}}, ChannelStable)
+		assert.ErrorContains(t, err, "no stable version found")
+	})
+}